@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// itemCommand creates a command converting each argument, or each line of stdin.
+// setup adds the flags specific to the command and returns the function creating the conversion,
+// which fails if the flags are invalid.
+func itemCommand(name, args, short string, setup func(fs *flag.FlagSet) func(codec *hashids.HashID) (func(string) record, error)) *command {
+	return &command{
+		name:  name,
+		args:  args + "...|-",
+		short: short,
+		long:  "Reads one item per line from stdin with -stdin or a single - argument.",
+		setup: func(fs *flag.FlagSet) func(args []string) int {
+			var codecOpts codecOptions
+			var itemOpts itemOptions
+			codecOpts.addFlags(fs)
+			itemOpts.addFlags(fs)
+			conversion := setup(fs)
+			return func(args []string) int {
+				codec, err := codecOpts.codec()
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+				convert, err := conversion(codec)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 2
+				}
+				return itemOpts.run(args, convert)
+			}
+		},
+	}
+}
+
+// fixedConversion returns an itemCommand setup without flags, always converting with convert
+func fixedConversion(convert func(codec *hashids.HashID, input string) record) func(fs *flag.FlagSet) func(codec *hashids.HashID) (func(string) record, error) {
+	return func(*flag.FlagSet) func(codec *hashids.HashID) (func(string) record, error) {
+		return func(codec *hashids.HashID) (func(string) record, error) {
+			return func(input string) record { return convert(codec, input) }, nil
+		}
+	}
+}
+
+var encodeCommand = itemCommand("encode", "<intlist|hex|uuid>", "encode lists of integers, hex or UUIDs to hashids",
+	func(fs *flag.FlagSet) func(codec *hashids.HashID) (func(string) record, error) {
+		var modeOpts modeOptions
+		modeOpts.addFlags(fs, true)
+		return modeOpts.encoder
+	})
+
+var decodeCommand = itemCommand("decode", "<hashid>", "decode hashids to lists of integers, hex or UUIDs",
+	func(fs *flag.FlagSet) func(codec *hashids.HashID) (func(string) record, error) {
+		var modeOpts modeOptions
+		modeOpts.addFlags(fs, false)
+		return modeOpts.decoder
+	})
+
+var hexEncodeCommand = itemCommand("hex encode", "<hex>", "encode hexadecimal strings to hashids", fixedConversion(encodeHex))
+
+var hexDecodeCommand = itemCommand("hex decode", "<hashid>", "decode hashids to hexadecimal strings", fixedConversion(decodeHex))
+
+var verifyCommand = itemCommand("verify", "<hashid>", "check that hashids decode with the codec",
+	fixedConversion(func(codec *hashids.HashID, input string) record {
+		r := record{Input: input}
+		if _, r.Err = codec.DecodeInt64WithError(input); r.Err == nil {
+			r.Text = "ok"
+			r.Value = true
+		}
+		return r
+	}))
+
+var inspectCommand = &command{
+	name:  "inspect",
+	args:  "<hashid>...|-",
+	short: "show how hashids decode step by step",
+	long: "Shows the guards, lottery, separators and numbers found in each hashid, and why it does not decode.\n" +
+		"The exit status is 1 if any hashid does not decode.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var itemOpts itemOptions
+		codecOpts.addFlags(fs)
+		itemOpts.addFlags(fs)
+		return func(args []string) int {
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			invalid := false
+			status := itemOpts.run(args, func(input string) record {
+				// The breakdown is written even when the hash does not decode
+				b, err := codec.Inspect(input)
+				if err != nil {
+					invalid = true
+				}
+				return record{Input: input, Text: formatBreakdown(b), Value: b}
+			})
+			if status == 0 && invalid {
+				status = 1
+			}
+			return status
+		}
+	},
+}
+
+// formatBreakdown describes b on several indented lines
+func formatBreakdown(b *hashids.Breakdown) string {
+	var sb strings.Builder
+	runes := func(label string, positions []hashids.RunePosition) {
+		fmt.Fprintf(&sb, "\n  %s:", label)
+		for _, rp := range positions {
+			fmt.Fprintf(&sb, " %q@%d", rp.Rune, rp.Position)
+		}
+	}
+	runes("guards", b.Guards)
+	if b.LotteryPosition >= 0 {
+		fmt.Fprintf(&sb, "\n  lottery: %q@%d", b.Lottery, b.LotteryPosition)
+	} else {
+		sb.WriteString("\n  lottery: none")
+	}
+	runes("separators", b.Separators)
+	for _, sub := range b.SubHashes {
+		fmt.Fprintf(&sb, "\n  %q@%d: %d (alphabet %s)", sub.Hash, sub.Position, sub.Number, sub.Alphabet)
+	}
+	fmt.Fprintf(&sb, "\n  reencoded: %s", b.Reencoded)
+	if b.Reason != "" {
+		fmt.Fprintf(&sb, "\n  invalid: %s", b.Reason)
+	} else {
+		sb.WriteString("\n  valid")
+	}
+	return sb.String()
+}
+
+// codecInfo describes the effective settings of a codec
+type codecInfo struct {
+	Alphabet      string `json:"alphabet"`
+	Separators    string `json:"separators"`
+	Guards        string `json:"guards"`
+	MinLength     int    `json:"min_length"`
+	ExactLength   int    `json:"exact_length,omitempty"`
+	MaxLength     int    `json:"max_length"`
+	MaxExactValue int64  `json:"max_exact_value,omitempty"`
+	Fingerprint   string `json:"fingerprint"`
+}
+
+// newCodecInfo returns the effective settings of codec, the minimum length is at least the exact length
+func newCodecInfo(codec *hashids.HashID) codecInfo {
+	config := codec.Config()
+	info := codecInfo{
+		Alphabet:    codec.Alphabet(),
+		Separators:  codec.Separators(),
+		Guards:      codec.Guards(),
+		MinLength:   config.MinLength,
+		ExactLength: config.ExactLength,
+		MaxLength:   codec.MaxEncodedLength(1),
+		Fingerprint: codec.Fingerprint(),
+	}
+	if config.ExactLength > 0 {
+		info.MaxExactValue = codec.MaxExactValue()
+		if config.ExactLength > info.MinLength {
+			info.MinLength = config.ExactLength
+		}
+	}
+	return info
+}
+
+var infoCommand = &command{
+	name:  "info",
+	short: "show the effective alphabet, separators, guards and lengths of the codec",
+	long:  "The alphabet is shuffled with the salt and does not contain the separators and guards.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var format string
+		codecOpts.addFlags(fs)
+		fs.StringVar(&format, `format`, "text", `output format: text or json`)
+		return func(args []string) int {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "info takes no arguments")
+				return 2
+			}
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			info := newCodecInfo(codec)
+			out := bufio.NewWriter(os.Stdout)
+			switch format {
+			case "text":
+				fmt.Fprintf(out, "alphabet: %s\n", info.Alphabet)
+				fmt.Fprintf(out, "separators: %s\n", info.Separators)
+				fmt.Fprintf(out, "guards: %s\n", info.Guards)
+				fmt.Fprintf(out, "min length: %d\n", info.MinLength)
+				if info.ExactLength > 0 {
+					fmt.Fprintf(out, "exact length: %d\n", info.ExactLength)
+					fmt.Fprintf(out, "max exact value: %s\n", strconv.FormatInt(info.MaxExactValue, 10))
+				}
+				fmt.Fprintf(out, "max length of a single number: %d\n", info.MaxLength)
+				fmt.Fprintf(out, "fingerprint: %s\n", info.Fingerprint)
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(info); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			default:
+				fmt.Fprintf(os.Stderr, "unknown format %q, expected text or json\n", format)
+				return 2
+			}
+			if err := out.Flush(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	},
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func TestNewCodecInfo(t *testing.T) {
+	for _, test := range []struct {
+		min, exact, expected int
+	}{{0, 0, 0}, {8, 0, 8}, {0, 6, 6}, {4, 6, 6}} {
+		hdata := hashids.NewData()
+		hdata.MinLength, hdata.ExactLength = test.min, test.exact
+		codec, err := hashids.NewWithData(hdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := newCodecInfo(codec)
+		if info.MinLength != test.expected || info.ExactLength != test.exact {
+			t.Errorf("Expected min length %d with -min %d -exact %d but got %d", test.expected, test.min, test.exact, info.MinLength)
+		}
+		if test.exact > 0 && info.MaxExactValue <= 0 {
+			t.Errorf("Expected a max exact value with -exact %d", test.exact)
+		}
+	}
+}
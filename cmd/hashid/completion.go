@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// The completion scripts and the man page are generated from the commands and their flags,
+// so they stay in sync with them.
+
+var shells = []string{"bash", "zsh", "fish"}
+
+var completionCommand = &command{
+	name:    "completion",
+	args:    "bash|zsh|fish",
+	short:   "print the shell completion script",
+	choices: shells,
+	long: "eg. for bash, add to ~/.bashrc:\n" +
+		"\tsource <(hashid completion bash)",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		return func(args []string) int {
+			if len(args) != 1 {
+				fmt.Fprintf(os.Stderr, "expected one shell of %s\n", strings.Join(shells, ", "))
+				return 2
+			}
+			generators := map[string]func(io.Writer){
+				"bash": writeBashCompletion,
+				"zsh":  writeZshCompletion,
+				"fish": writeFishCompletion,
+			}
+			generate, ok := generators[args[0]]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown shell %q, expected one of %s\n", args[0], strings.Join(shells, ", "))
+				return 2
+			}
+			out := bufio.NewWriter(os.Stdout)
+			generate(out)
+			if err := out.Flush(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	},
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagNames returns the flags as completed by shells, with their dash. Only the flags taking a value
+// are returned if values is set.
+func flagNames(flags []*flag.Flag, values bool) string {
+	var names []string
+	for _, f := range flags {
+		if !values || !isBoolFlag(f) {
+			names = append(names, "-"+f.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// flagUsage returns the first line of the usage of f, without the value name
+func flagUsage(f *flag.Flag) string {
+	_, usage := flag.UnquoteUsage(f)
+	usage, _, _ = strings.Cut(usage, "\n")
+	return usage
+}
+
+// shellQuote quotes s for POSIX shells and fish
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// subcommands returns the commands whose name starts with the first word of cmd, when it has several
+func subcommands(word string) []*command {
+	var result []*command
+	for _, cmd := range commands {
+		if fields := strings.Fields(cmd.name); len(fields) > 1 && fields[0] == word {
+			result = append(result, cmd)
+		}
+	}
+	return result
+}
+
+// topDescription returns the description of the first word of the command names
+func topDescription(word string) string {
+	if sub := subcommands(word); len(sub) > 0 {
+		names := make([]string, len(sub))
+		for i, cmd := range sub {
+			names[i] = cmd.name
+		}
+		return strings.Join(names, ", ")
+	}
+	cmd, _ := findCommand([]string{word})
+	return cmd.short
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# bash completion for %s, generated by '%s completion bash'\n\n", program, program)
+	fmt.Fprintf(w, "_%s() {\n", program)
+	fmt.Fprint(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprint(w, "\tlocal line=\"${COMP_WORDS[*]:1:COMP_CWORD-1} \"\n")
+	fmt.Fprint(w, "\tlocal flags values words\n")
+	fmt.Fprint(w, "\tcase \"$line\" in\n")
+	// Longer names first, so that "hex encode" is not taken for a prefix
+	for _, cmd := range commands {
+		if len(strings.Fields(cmd.name)) > 1 {
+			writeBashCase(w, cmd)
+		}
+	}
+	for _, cmd := range commands {
+		if len(strings.Fields(cmd.name)) == 1 {
+			writeBashCase(w, cmd)
+		}
+	}
+	for _, word := range commandWords(0) {
+		if sub := subcommands(word); len(sub) > 0 {
+			var words []string
+			for _, cmd := range sub {
+				words = append(words, strings.Fields(cmd.name)[1])
+			}
+			fmt.Fprintf(w, "\t%q)\n\t\twords=%s\n\t\t;;\n", word+" ", shellQuote(strings.Join(words, " ")))
+		}
+	}
+	legacyFlags := legacyCommand.flags()
+	fmt.Fprintf(w, "\t*)\n\t\tflags=%s\n\t\tvalues=%s\n", shellQuote(flagNames(legacyFlags, false)), shellQuote(flagNames(legacyFlags, true)))
+	fmt.Fprintf(w, "\t\tif [[ $line == \" \" ]]; then\n\t\t\twords=%s\n\t\tfi\n\t\t;;\n", shellQuote(strings.Join(commandWords(0), " ")))
+	fmt.Fprint(w, "\tesac\n\n")
+	fmt.Fprint(w, "\tif [[ \" $values \" == *\" $prev \"* ]]; then\n\t\treturn\n\tfi\n")
+	fmt.Fprint(w, "\tif [[ $cur == -* ]]; then\n\t\tCOMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	fmt.Fprint(w, "\telif [[ -n $words ]]; then\n\t\tCOMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n\tfi\n")
+	fmt.Fprint(w, "}\n\n")
+	fmt.Fprintf(w, "complete -o default -F _%s %s\n", program, program)
+}
+
+func writeBashCase(w io.Writer, cmd *command) {
+	flags := cmd.flags()
+	fmt.Fprintf(w, "\t%q*)\n", cmd.name+" ")
+	fmt.Fprintf(w, "\t\tflags=%s\n\t\tvalues=%s\n", shellQuote(flagNames(flags, false)), shellQuote(flagNames(flags, true)))
+	if len(cmd.choices) > 0 {
+		fmt.Fprintf(w, "\t\twords=%s\n", shellQuote(strings.Join(cmd.choices, " ")))
+	}
+	fmt.Fprint(w, "\t\t;;\n")
+}
+
+// zshEscape escapes the characters with a meaning in the descriptions of _arguments and _describe
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+// zshArguments returns the _arguments specs of cmd
+func zshArguments(cmd *command) []string {
+	var specs []string
+	for _, f := range cmd.flags() {
+		spec := fmt.Sprintf("-%s[%s]", f.Name, zshEscape(flagUsage(f)))
+		if !isBoolFlag(f) {
+			name, _ := flag.UnquoteUsage(f)
+			spec += fmt.Sprintf(":%s:_files", zshEscape(name))
+		}
+		specs = append(specs, shellQuote(spec))
+	}
+	if len(cmd.choices) > 0 {
+		specs = append(specs, shellQuote(fmt.Sprintf("1: :(%s)", strings.Join(cmd.choices, " "))))
+	} else {
+		specs = append(specs, shellQuote("*: :_files"))
+	}
+	return specs
+}
+
+func writeZshArguments(w io.Writer, indent string, cmd *command) {
+	fmt.Fprintf(w, "%s_arguments \\\n%s\t%s\n", indent, indent, strings.Join(zshArguments(cmd), " \\\n"+indent+"\t"))
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef %s\n\n# zsh completion for %s, generated by '%s completion zsh'\n\n", program, program, program)
+	fmt.Fprintf(w, "_%s() {\n", program)
+	fmt.Fprint(w, "\tlocal curcontext=\"$curcontext\" state line\n\tlocal -a commands subcommands\n\tcommands=(\n")
+	for _, word := range commandWords(0) {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(word+":"+zshEscape(topDescription(word))))
+	}
+	fmt.Fprint(w, "\t)\n\n")
+
+	legacySpecs := zshArguments(legacyCommand)
+	// The legacy positional arguments are replaced by the commands
+	legacySpecs = append(legacySpecs[:len(legacySpecs)-1], shellQuote("1: :->command"), shellQuote("*:: :->args"))
+	fmt.Fprintf(w, "\t_arguments -C \\\n\t\t%s\n\n", strings.Join(legacySpecs, " \\\n\t\t"))
+
+	fmt.Fprint(w, "\tcase $state in\n\tcommand)\n\t\t_describe -t commands command commands\n\t\t;;\n\targs)\n")
+	fmt.Fprint(w, "\t\tcase $words[1] in\n")
+	for _, word := range commandWords(0) {
+		fmt.Fprintf(w, "\t\t%s)\n", word)
+		sub := subcommands(word)
+		if len(sub) == 0 {
+			cmd, _ := findCommand([]string{word})
+			writeZshArguments(w, "\t\t\t", cmd)
+			fmt.Fprint(w, "\t\t\t;;\n")
+			continue
+		}
+		fmt.Fprint(w, "\t\t\tif (( CURRENT == 2 )); then\n\t\t\t\tsubcommands=(\n")
+		for _, cmd := range sub {
+			fmt.Fprintf(w, "\t\t\t\t\t%s\n", shellQuote(strings.Fields(cmd.name)[1]+":"+zshEscape(cmd.short)))
+		}
+		fmt.Fprintf(w, "\t\t\t\t)\n\t\t\t\t_describe -t commands %s subcommands\n\t\t\t\treturn\n\t\t\tfi\n", shellQuote(word+" command"))
+		fmt.Fprint(w, "\t\t\tshift words\n\t\t\t(( CURRENT-- ))\n\t\t\tcase $words[1] in\n")
+		for _, cmd := range sub {
+			fmt.Fprintf(w, "\t\t\t%s)\n", strings.Fields(cmd.name)[1])
+			writeZshArguments(w, "\t\t\t\t", cmd)
+			fmt.Fprint(w, "\t\t\t\t;;\n")
+		}
+		fmt.Fprint(w, "\t\t\tesac\n\t\t\t;;\n")
+	}
+	fmt.Fprint(w, "\t\tesac\n\t\t;;\n\tesac\n}\n\n")
+	fmt.Fprintf(w, "_%s \"$@\"\n", program)
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for %s, generated by '%s completion fish'\n\n", program, program)
+	top := commandWords(0)
+	for _, word := range top {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -f -a %s -d %s\n", program, word, shellQuote(topDescription(word)))
+	}
+	writeFishFlags(w, "__fish_use_subcommand", legacyCommand)
+
+	for _, cmd := range commands {
+		fields := strings.Fields(cmd.name)
+		var conditions []string
+		for _, field := range fields {
+			conditions = append(conditions, "__fish_seen_subcommand_from "+field)
+		}
+		if len(fields) == 1 {
+			// Exclude the commands with several words starting with another word, like hex encode
+			for _, word := range top {
+				if len(subcommands(word)) > 0 && word != fields[0] {
+					conditions = append(conditions, "not __fish_seen_subcommand_from "+word)
+				}
+			}
+		}
+		writeFishFlags(w, strings.Join(conditions, "; and "), cmd)
+		if len(cmd.choices) > 0 {
+			fmt.Fprintf(w, "complete -c %s -n %s -f -a %s\n", program, shellQuote(strings.Join(conditions, "; and ")), shellQuote(strings.Join(cmd.choices, " ")))
+		}
+	}
+
+	for _, word := range top {
+		sub := subcommands(word)
+		if len(sub) == 0 {
+			continue
+		}
+		var words []string
+		for _, cmd := range sub {
+			words = append(words, strings.Fields(cmd.name)[1])
+		}
+		condition := fmt.Sprintf("__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s", word, strings.Join(words, " "))
+		for _, cmd := range sub {
+			fmt.Fprintf(w, "complete -c %s -n %s -f -a %s -d %s\n", program, shellQuote(condition), strings.Fields(cmd.name)[1], shellQuote(cmd.short))
+		}
+	}
+}
+
+func writeFishFlags(w io.Writer, condition string, cmd *command) {
+	for _, f := range cmd.flags() {
+		required := ""
+		if !isBoolFlag(f) {
+			required = " -r"
+		}
+		fmt.Fprintf(w, "complete -c %s -n %s -o %s%s -d %s\n", program, shellQuote(condition), f.Name, required, shellQuote(flagUsage(f)))
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Error policies of the csv command
+const (
+	onErrorSkip  = "skip"
+	onErrorBlank = "blank"
+	onErrorAbort = "abort"
+)
+
+// csvOptions are the flags of the commands converting columns of a CSV stream
+type csvOptions struct {
+	tsv, noHeader bool
+	onError       string
+}
+
+func (o *csvOptions) addFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.tsv, `tsv`, false, `read and write TSV instead of CSV`)
+	fs.BoolVar(&o.noHeader, `no-header`, false, `the first row is data, columns are 1-based indices`)
+	fs.StringVar(&o.onError, `on-error`, onErrorAbort, `what to do with a row which fails: skip, blank or abort`)
+}
+
+// checkOnError reports an unknown -on-error policy
+func checkOnError(onError string) error {
+	switch onError {
+	case onErrorSkip, onErrorBlank, onErrorAbort:
+		return nil
+	}
+	return fmt.Errorf("unknown -on-error policy %q", onError)
+}
+
+// columnConversion converts the cells of the comma separated columns cols
+type columnConversion struct {
+	cols    string
+	convert func(string) record
+}
+
+var csvCommand = &command{
+	name:  "csv",
+	args:  "-encode-cols=<cols> -decode-cols=<cols> < input.csv > output.csv",
+	short: "convert columns of a CSV or TSV stream between integers and hashids",
+	long:  "Columns are header names, or 1-based indices with -no-header.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var opts csvOptions
+		var encodeCols, decodeCols, separator string
+		codecOpts.addFlags(fs)
+		fs.StringVar(&encodeCols, `encode-cols`, "", `comma separated columns of integers to encode`)
+		fs.StringVar(&decodeCols, `decode-cols`, "", `comma separated columns of hashids to decode`)
+		opts.addFlags(fs)
+		fs.StringVar(&separator, `sep`, ",", `separator for integers in a cell`)
+		return func(args []string) int {
+			if err := checkOnError(opts.onError); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			if encodeCols == "" && decodeCols == "" {
+				fmt.Fprintln(os.Stderr, "at least one of -encode-cols or -decode-cols is required")
+				return 2
+			}
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return runCSV(&opts, []columnConversion{
+				{encodeCols, func(cell string) record { return encodeIntList(codec, cell, separator) }},
+				{decodeCols, func(cell string) record { return decodeIntList(codec, cell, separator) }},
+			})
+		}
+	},
+}
+
+// runCSV converts stdin to stdout and returns the exit status
+func runCSV(opts *csvOptions, conversions []columnConversion) int {
+	failed, err := transformCSV(os.Stdin, os.Stdout, os.Stderr, opts, conversions)
+	if err != nil && err != errStopped {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if err != nil || failed {
+		return 1
+	}
+	return 0
+}
+
+// transformCSV converts the columns of in to out, reporting errors for each row to errOut.
+// The cells which are not converted are copied unchanged, with their quotes and line endings.
+// It returns whether any row failed, and an error if the stream could not be converted.
+func transformCSV(in io.Reader, out, errOut io.Writer, opts *csvOptions, conversions []columnConversion) (bool, error) {
+	r := &csvReader{r: bufio.NewReader(in), comma: ',', quotes: true}
+	if opts.tsv {
+		r.comma, r.quotes = '\t', false
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	indices := make([][]int, len(conversions))
+	var original []string
+	failed := false
+	for row := 1; ; row++ {
+		fields, raw, end, err := r.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return failed, err
+		}
+		if len(raw) == 1 && raw[0] == "" {
+			// Keep the empty lines, which are not rows
+			w.WriteString(end)
+			row--
+			continue
+		}
+
+		if row == 1 {
+			for i, conversion := range conversions {
+				if indices[i], err = columnIndices(conversion.cols, fields, opts.noHeader); err != nil {
+					return failed, err
+				}
+			}
+			if !opts.noHeader {
+				w.WriteString(strings.Join(raw, string(r.comma)) + end)
+				continue
+			}
+		}
+
+		original = append(original[:0], fields...)
+		var rowErrs []error
+		for i, conversion := range conversions {
+			rowErrs = append(rowErrs, convertColumns(fields, indices[i], conversion.convert, opts.onError == onErrorBlank)...)
+		}
+		for i := range fields {
+			if fields[i] == original[i] {
+				continue
+			}
+			if raw[i], err = r.quote(fields[i]); err != nil {
+				rowErrs = append(rowErrs, fmt.Errorf("column %d: %w", i+1, err))
+				raw[i] = ""
+			}
+		}
+		if len(rowErrs) > 0 {
+			failed = true
+			if err := w.Flush(); err != nil {
+				return failed, err
+			}
+			for _, rowErr := range rowErrs {
+				fmt.Fprintf(errOut, "row %d: %s\n", row, rowErr)
+			}
+			switch opts.onError {
+			case onErrorSkip:
+				continue
+			case onErrorAbort:
+				return failed, errStopped
+			}
+		}
+		w.WriteString(strings.Join(raw, string(r.comma)) + end)
+	}
+	return failed, w.Flush()
+}
+
+// csvReader reads CSV or TSV records along with the text of each field as it is in the input,
+// so that the fields which are not converted can be written back unchanged.
+// TSV fields are never quoted, a quote is part of the value.
+type csvReader struct {
+	r      *bufio.Reader
+	comma  byte
+	quotes bool
+	line   int
+}
+
+// read returns the values of the fields of the next record, their text in the input and the line ending
+// of the record, empty for the last line when it has none. It returns io.EOF at the end of the input.
+func (c *csvReader) read() (fields, raw []string, end string, err error) {
+	text, err := c.r.ReadString('\n')
+	if text == "" || err != nil && err != io.EOF {
+		return nil, nil, "", err
+	}
+	c.line++
+	line := c.line
+	for pos := 0; ; {
+		if !c.quotes || pos == len(text) || text[pos] != '"' {
+			field := text[pos:]
+			i := strings.IndexByte(field, c.comma)
+			if i >= 0 {
+				field = field[:i]
+			} else {
+				field, end = splitLineEnding(field)
+			}
+			if c.quotes && strings.IndexByte(field, '"') >= 0 {
+				return nil, nil, "", fmt.Errorf("line %d: bare \" in a field which is not quoted", line)
+			}
+			fields, raw = append(fields, field), append(raw, field)
+			if i < 0 {
+				return fields, raw, end, nil
+			}
+			pos += i + 1
+			continue
+		}
+
+		var value strings.Builder
+		i := pos + 1
+		for {
+			j := strings.IndexByte(text[i:], '"')
+			if j < 0 {
+				// The quoted field continues on the next line
+				more, err := c.r.ReadString('\n')
+				if more == "" || err != nil && err != io.EOF {
+					return nil, nil, "", fmt.Errorf("line %d: missing closing \" in quoted field", line)
+				}
+				c.line++
+				text += more
+				continue
+			}
+			value.WriteString(text[i : i+j])
+			i += j + 1
+			if i < len(text) && text[i] == '"' {
+				value.WriteByte('"')
+				i++
+				continue
+			}
+			break
+		}
+		fields = append(fields, strings.ReplaceAll(value.String(), "\r\n", "\n"))
+		raw = append(raw, text[pos:i])
+		if i < len(text) && text[i] == c.comma {
+			pos = i + 1
+			continue
+		}
+		rest, end := splitLineEnding(text[i:])
+		if rest != "" {
+			return nil, nil, "", fmt.Errorf("line %d: extraneous \" in quoted field", line)
+		}
+		return fields, raw, end, nil
+	}
+}
+
+// quote returns the text of a field with value, quoted in CSV if needed
+func (c *csvReader) quote(value string) (string, error) {
+	if !c.quotes {
+		if strings.ContainsAny(value, "\t\r\n") {
+			return "", fmt.Errorf("%q cannot be written in TSV", value)
+		}
+		return value, nil
+	}
+	if value == "" || !strings.ContainsAny(value, "\"\r\n"+string(c.comma)) && value[0] != ' ' && value[0] != '\t' {
+		return value, nil
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`, nil
+}
+
+// splitLineEnding splits text before its \n or \r\n ending
+func splitLineEnding(text string) (string, string) {
+	if strings.HasSuffix(text, "\r\n") {
+		return text[:len(text)-2], "\r\n"
+	}
+	if strings.HasSuffix(text, "\n") {
+		return text[:len(text)-1], "\n"
+	}
+	return text, ""
+}
+
+// convertColumns converts the fields at indices in place, blanking the cells which fail if blank is set.
+// Empty cells are kept.
+func convertColumns(fields []string, indices []int, convert func(string) record, blank bool) []error {
+	var errs []error
+	for _, i := range indices {
+		if i >= len(fields) || fields[i] == "" {
+			continue
+		}
+		r := convert(fields[i])
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("column %d: %s: %w", i+1, fields[i], r.Err))
+			if blank {
+				fields[i] = ""
+			}
+			continue
+		}
+		fields[i] = r.Text
+	}
+	return errs
+}
+
+// columnIndices returns the 0-based indices of comma separated columns, given by name in the header or by 1-based index
+func columnIndices(cols string, header []string, noHeader bool) ([]int, error) {
+	if cols == "" {
+		return nil, nil
+	}
+	var indices []int
+	for _, col := range strings.Split(cols, ",") {
+		if noHeader {
+			i, err := strconv.Atoi(col)
+			if err != nil || i < 1 {
+				return nil, fmt.Errorf("invalid column index %q", col)
+			}
+			indices = append(indices, i-1)
+			continue
+		}
+		found := false
+		for i, name := range header {
+			if name == col {
+				indices = append(indices, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", col)
+		}
+	}
+	return indices, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func encodeTest(t *testing.T, codec *hashids.HashID, numbers ...int64) string {
+	t.Helper()
+	hash, err := codec.EncodeInt64(numbers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestTransformCSV(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	encode := []columnConversion{{"id", func(cell string) record { return encodeIntList(codec, cell, ",") }}}
+	one, two := encodeTest(t, codec, 1), encodeTest(t, codec, 2)
+
+	tests := []struct {
+		name        string
+		opts        csvOptions
+		in, out     string
+		errOut      string
+		failed      bool
+		err         error
+		conversions []columnConversion
+	}{
+		{
+			name: "unconverted cells are kept as is",
+			opts: csvOptions{onError: onErrorAbort},
+			in:   "id,\"name\",note\r\n1,\"plain\",x\r\n\r\n\"2\",\"a \"\"quote\"\"\",\"multi\nline\"",
+			out:  "id,\"name\",note\r\n" + one + ",\"plain\",x\r\n\r\n" + two + ",\"a \"\"quote\"\"\",\"multi\nline\"",
+		},
+		{
+			name:   "TSV is never quoted",
+			opts:   csvOptions{tsv: true, onError: onErrorAbort},
+			in:     "id\tnote\n1\ta\"b\n\"2\"\t\"c\"\n",
+			out:    "id\tnote\n" + one + "\ta\"b\n",
+			errOut: "row 3: column 1: \"2\": ",
+			failed: true,
+			err:    errStopped,
+		},
+		{
+			name:        "skip",
+			opts:        csvOptions{noHeader: true, onError: onErrorSkip},
+			in:          "x,1\ny,-1\nz,2\n",
+			out:         "x," + one + "\nz," + two + "\n",
+			errOut:      "row 2: column 2: -1: ",
+			failed:      true,
+			conversions: []columnConversion{{"2", encode[0].convert}},
+		},
+		{
+			name:   "blank",
+			opts:   csvOptions{onError: onErrorBlank},
+			in:     "id,n\na,1\n",
+			out:    "id,n\n,1\n",
+			errOut: "row 2: column 1: a: ",
+			failed: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conversions := test.conversions
+			if conversions == nil {
+				conversions = encode
+			}
+			var out, errOut bytes.Buffer
+			failed, err := transformCSV(strings.NewReader(test.in), &out, &errOut, &test.opts, conversions)
+			if err != test.err || failed != test.failed {
+				t.Errorf("Expected failed %v and error %v but got %v and %v", test.failed, test.err, failed, err)
+			}
+			if out.String() != test.out {
+				t.Errorf("Expected output %q but got %q", test.out, out.String())
+			}
+			if !strings.HasPrefix(errOut.String(), test.errOut) || (test.errOut == "") != (errOut.Len() == 0) {
+				t.Errorf("Expected errors starting with %q but got %q", test.errOut, errOut.String())
+			}
+		})
+	}
+}
+
+func TestTransformCSVWithInvalidInput(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	conversions := []columnConversion{{"id", func(cell string) record { return decodeIntList(codec, cell, ",") }}}
+	for in, expected := range map[string]string{
+		"id\na\"b\n":   `line 2: bare " in a field which is not quoted`,
+		"id\n\"a\"b\n": `line 2: extraneous " in quoted field`,
+		"id\n\"a\nb\n": `line 2: missing closing " in quoted field`,
+		"name\nx\n":    `unknown column "id"`,
+	} {
+		var out, errOut bytes.Buffer
+		_, err := transformCSV(strings.NewReader(in), &out, &errOut, &csvOptions{onError: onErrorAbort}, conversions)
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error `%s` for %q but got `%v`", expected, in, err)
+		}
+	}
+}
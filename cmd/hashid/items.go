@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// Environment variables read by codecOptions. They do not start with HASHID_, the prefix of the
+// variables read by hashids.ParseEnv in its documentation, which rejects the variables it does not know.
+const (
+	envSalt        = "HASHIDCLI_SALT"
+	envAlphabet    = "HASHIDCLI_ALPHABET"
+	envMinLength   = "HASHIDCLI_MIN_LENGTH"
+	envExactLength = "HASHIDCLI_EXACT_LENGTH"
+)
+
+// codecOptionsUsage explains where codecOptions take the settings from
+const codecOptionsUsage = "The codec settings are taken, in order of precedence, from the -salt, -alphabet, -min\n" +
+	"and -exact flags or -salt-file, the " + envSalt + ", " + envAlphabet + ", " + envMinLength + "\n" +
+	"and " + envExactLength + " environment variables, the -profile of the -config file, and the defaults."
+
+// codecOptions are the flags creating the codec
+type codecOptions struct {
+	fs         *flag.FlagSet
+	params     hashids.HashIDData
+	saltFile   string
+	configFile string
+	profile    string
+}
+
+func (o *codecOptions) addFlags(fs *flag.FlagSet) {
+	o.fs = fs
+	fs.StringVar(&o.params.Salt, `salt`, "", `salt, visible in the process list, prefer -salt-file or `+envSalt)
+	fs.StringVar(&o.params.Alphabet, `alphabet`, hashids.DefaultAlphabet, `minimum 16 characters`)
+	fs.IntVar(&o.params.MinLength, `min`, 0, `minimum length (for encoding)`)
+	fs.IntVar(&o.params.ExactLength, `exact`, 0, `exact length (for encoding)`)
+	fs.StringVar(&o.saltFile, `salt-file`, "", `read the salt from a file, without the trailing newline`)
+	fs.StringVar(&o.configFile, `config`, "", `JSON, YAML or TOML file of named profiles with the codec settings`)
+	fs.StringVar(&o.profile, `profile`, "", `profile of the -config file, optional if it has a single one`)
+}
+
+// codec creates the codec from the flags set, the environment, the config file and the defaults
+func (o *codecOptions) codec() (*hashids.HashID, error) {
+	params := hashids.NewData()
+	if o.profile != "" && o.configFile == "" {
+		return nil, errors.New("-profile requires -config")
+	}
+	if o.configFile != "" {
+		profile, err := o.configProfile()
+		if err != nil {
+			return nil, err
+		}
+		params = profile
+	}
+
+	if salt, ok := os.LookupEnv(envSalt); ok {
+		params.Salt = salt
+	}
+	if alphabet, ok := os.LookupEnv(envAlphabet); ok {
+		params.Alphabet = alphabet
+	}
+	lengths := []struct {
+		name string
+		dst  *int
+	}{{envMinLength, &params.MinLength}, {envExactLength, &params.ExactLength}}
+	for _, length := range lengths {
+		if value, ok := os.LookupEnv(length.name); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", length.name, err)
+			}
+			*length.dst = n
+		}
+	}
+
+	set := make(map[string]bool)
+	o.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["salt"] && set["salt-file"] {
+		return nil, errors.New("-salt and -salt-file cannot be used together")
+	}
+	if o.saltFile != "" {
+		salt, err := os.ReadFile(o.saltFile)
+		if err != nil {
+			return nil, err
+		}
+		params.Salt = strings.TrimRight(string(salt), "\r\n")
+	}
+	if set["salt"] {
+		params.Salt = o.params.Salt
+	}
+	if set["alphabet"] {
+		params.Alphabet = o.params.Alphabet
+	}
+	if set["min"] {
+		params.MinLength = o.params.MinLength
+	}
+	if set["exact"] {
+		params.ExactLength = o.params.ExactLength
+	}
+	return hashids.NewWithData(params)
+}
+
+// configProfile returns the settings of the selected profile in the config file
+func (o *codecOptions) configProfile() (*hashids.HashIDData, error) {
+	profiles, err := hashids.ParseConfigFile(o.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", o.configFile, err)
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profile := o.profile
+	if profile == "" {
+		if len(names) != 1 {
+			return nil, fmt.Errorf("%s: -profile is required to choose one of %s", o.configFile, strings.Join(names, ", "))
+		}
+		profile = names[0]
+	}
+	params, ok := profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown profile %q, expected one of %s", o.configFile, profile, strings.Join(names, ", "))
+	}
+	return params, nil
+}
+
+// itemOptions are the flags of the commands converting each argument or line of stdin
+type itemOptions struct {
+	stdin, failFast bool
+	format          string
+}
+
+func (o *itemOptions) addFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.stdin, `stdin`, false, `read one item per line from stdin (same as -)`)
+	fs.BoolVar(&o.failFast, `fail-fast`, false, `stop at the first error`)
+	fs.StringVar(&o.format, `format`, "text", `output format: `+strings.Join(formats, ", "))
+}
+
+// run converts each item of args, or of stdin, and writes the results. It returns the exit status.
+func (o *itemOptions) run(args []string, convert func(string) record) int {
+	return o.stream(args, os.Stdin, os.Stdout, os.Stderr, convert)
+}
+
+// stream is run reading the items from in instead of stdin and writing to out and errOut
+func (o *itemOptions) stream(args []string, in io.Reader, w, errOut io.Writer, convert func(string) record) int {
+	stdin := o.stdin
+	if len(args) == 1 && args[0] == "-" {
+		stdin = true
+		args = nil
+	}
+
+	out, err := newRecordWriter(o.format, w, errOut)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	failed := false
+	items := forEachArg(args)
+	if stdin {
+		items = forEachLine(in)
+	}
+	var writeErr error
+	err = items(func(input string) bool {
+		r := convert(input)
+		if r.Err != nil {
+			failed = true
+		}
+		if writeErr = out.write(r); writeErr != nil {
+			return false
+		}
+		return r.Err == nil || !o.failFast
+	})
+	if writeErr != nil {
+		err = writeErr
+	}
+	if closeErr := out.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && err != errStopped {
+		fmt.Fprintln(errOut, err)
+	}
+	if err != nil || failed {
+		return 1
+	}
+	return 0
+}
+
+// errStopped is returned when the callback of an iteration asked to stop
+var errStopped = errors.New("stopped at the first error")
+
+// forEachArg returns an iteration over args
+func forEachArg(args []string) func(func(string) bool) error {
+	return func(fn func(string) bool) error {
+		for _, arg := range args {
+			if !fn(arg) {
+				return errStopped
+			}
+		}
+		return nil
+	}
+}
+
+// forEachLine returns an iteration over the lines of r which are not blank
+func forEachLine(r io.Reader) func(func(string) bool) error {
+	return func(fn func(string) bool) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if !fn(line) {
+				return errStopped
+			}
+		}
+		return scanner.Err()
+	}
+}
+
+func encodeIntList(codec *hashids.HashID, input, separator string) record {
+	r := record{Input: input}
+	spl := strings.Split(input, separator)
+	ints := make([]int64, len(spl))
+	for i, s := range spl {
+		ints[i], r.Err = strconv.ParseInt(s, 0, 64)
+		if r.Err != nil {
+			return r
+		}
+	}
+	r.Text, r.Err = codec.EncodeInt64(ints)
+	if r.Err == nil {
+		r.Value = r.Text
+	}
+	return r
+}
+
+func decodeIntList(codec *hashids.HashID, input, separator string) record {
+	r := record{Input: input}
+	result, err := codec.DecodeInt64WithError(input)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	var str []byte
+	for _, x := range result {
+		if len(str) != 0 {
+			str = append(str, separator...)
+		}
+		str = strconv.AppendInt(str, x, 10)
+	}
+	r.Text = string(str)
+	r.Value = result
+	return r
+}
+
+func encodeHex(codec *hashids.HashID, input string) record {
+	r := record{Input: input}
+	r.Text, r.Err = codec.EncodeHex(input)
+	if r.Err == nil {
+		r.Value = r.Text
+	}
+	return r
+}
+
+func decodeHex(codec *hashids.HashID, input string) record {
+	r := record{Input: input}
+	r.Text, r.Err = codec.DecodeHex(input)
+	if r.Err == nil {
+		r.Value = r.Text
+	}
+	return r
+}
+
+// modeOptions are the flags choosing the type of the items, lists of integers by default
+type modeOptions struct {
+	separator       string
+	hex, uuid, auto bool
+}
+
+func (o *modeOptions) addFlags(fs *flag.FlagSet, encode bool) {
+	fs.StringVar(&o.separator, `sep`, ",", `separator for integers`)
+	fs.BoolVar(&o.hex, `hex`, false, `items are hexadecimal strings`)
+	fs.BoolVar(&o.uuid, `uuid`, false, `items are dashed UUIDs`)
+	if encode {
+		fs.BoolVar(&o.auto, `auto`, false, `detect whether each item is a UUID, a list of integers or a hexadecimal string, in this order (for encoding)`)
+	}
+}
+
+func (o *modeOptions) check() error {
+	modes := 0
+	for _, set := range []bool{o.hex, o.uuid, o.auto} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of -hex, -uuid and -auto can be used")
+	}
+	return nil
+}
+
+// encoder returns the conversion encoding the items
+func (o *modeOptions) encoder(codec *hashids.HashID) (func(string) record, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	return func(input string) record {
+		switch {
+		case o.hex:
+			return encodeHex(codec, input)
+		case o.uuid:
+			return encodeUUID(codec, input)
+		case o.auto:
+			if _, err := parseUUID(input); err == nil {
+				return encodeUUID(codec, input)
+			}
+			if isIntList(input, o.separator) || !isHex(input) {
+				return encodeIntList(codec, input, o.separator)
+			}
+			return encodeHex(codec, input)
+		}
+		return encodeIntList(codec, input, o.separator)
+	}, nil
+}
+
+// decoder returns the conversion decoding the items
+func (o *modeOptions) decoder(codec *hashids.HashID) (func(string) record, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	if o.auto {
+		return nil, errors.New("-auto only applies to encoding, use -hex or -uuid to decode")
+	}
+	return func(input string) record {
+		switch {
+		case o.hex:
+			return decodeHex(codec, input)
+		case o.uuid:
+			return decodeUUID(codec, input)
+		}
+		return decodeIntList(codec, input, o.separator)
+	}, nil
+}
+
+// isIntList returns whether s is a list of integers separated by separator, even if they overflow
+func isIntList(s, separator string) bool {
+	for _, part := range strings.Split(s, separator) {
+		if _, err := strconv.ParseInt(part, 0, 64); err != nil {
+			if numErr, ok := err.(*strconv.NumError); !ok || numErr.Err != strconv.ErrRange {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// A UUID is encoded as three numbers of uuidChunks hexadecimal digits each, which fit in int64
+var uuidChunks = [3]int{15, 15, 2}
+
+// parseUUID returns the 32 lowercase hexadecimal digits of a dashed UUID
+func parseUUID(s string) (string, error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return "", fmt.Errorf("invalid UUID %q, expected xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", s)
+	}
+	digits := s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if !isHex(digits) {
+		return "", fmt.Errorf("invalid UUID %q, expected hexadecimal digits", s)
+	}
+	return strings.ToLower(digits), nil
+}
+
+func encodeUUID(codec *hashids.HashID, input string) record {
+	r := record{Input: input}
+	digits, err := parseUUID(input)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	numbers := make([]int64, 0, len(uuidChunks))
+	for _, chunk := range uuidChunks {
+		n, _ := strconv.ParseInt(digits[:chunk], 16, 64)
+		numbers = append(numbers, n)
+		digits = digits[chunk:]
+	}
+	r.Text, r.Err = codec.EncodeInt64(numbers)
+	if r.Err == nil {
+		r.Value = r.Text
+	}
+	return r
+}
+
+func decodeUUID(codec *hashids.HashID, input string) record {
+	r := record{Input: input}
+	numbers, err := codec.DecodeInt64WithError(input)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if len(numbers) != len(uuidChunks) {
+		r.Err = fmt.Errorf("decoded %d numbers instead of the %d of a UUID", len(numbers), len(uuidChunks))
+		return r
+	}
+	var digits strings.Builder
+	for i, chunk := range uuidChunks {
+		hex := strconv.FormatInt(numbers[i], 16)
+		if len(hex) > chunk {
+			r.Err = fmt.Errorf("number %d does not fit in %d hexadecimal digits of a UUID", numbers[i], chunk)
+			return r
+		}
+		digits.WriteString(strings.Repeat("0", chunk-len(hex)))
+		digits.WriteString(hex)
+	}
+	s := digits.String()
+	r.Text = s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+	r.Value = r.Text
+	return r
+}
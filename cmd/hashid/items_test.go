@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// parseCodecOptions returns the codec created by the codec flags in args
+func parseCodecOptions(args ...string) (*hashids.HashID, error) {
+	var opts codecOptions
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts.addFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return opts.codec()
+}
+
+func TestCodecOptionsPrecedence(t *testing.T) {
+	for _, name := range []string{envSalt, envAlphabet, envMinLength, envExactLength} {
+		// Restored after the test
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	dir := t.TempDir()
+	config := filepath.Join(dir, "hashid.toml")
+	saltFile := filepath.Join(dir, "salt")
+	os.WriteFile(config, []byte("[users]\nsalt = \"config salt\"\nmin_length = 8\n\n[orders]\nsalt = \"orders\"\n"), 0o644)
+	os.WriteFile(saltFile, []byte("file salt\n"), 0o644)
+
+	tests := []struct {
+		args      []string
+		env       string
+		salt      string
+		minLength int
+	}{
+		{nil, "", "", 0},
+		{[]string{"-config", config, "-profile", "users"}, "", "config salt", 8},
+		{[]string{"-config", config, "-profile", "users"}, "env salt", "env salt", 8},
+		{[]string{"-config", config, "-profile", "users", "-salt-file", saltFile}, "env salt", "file salt", 8},
+		{[]string{"-config", config, "-profile", "users", "-salt", "flag salt", "-min", "4"}, "env salt", "flag salt", 4},
+	}
+	for _, test := range tests {
+		if test.env != "" {
+			t.Setenv(envSalt, test.env)
+		} else {
+			os.Unsetenv(envSalt)
+		}
+		codec, err := parseCodecOptions(test.args...)
+		if err != nil {
+			t.Fatalf("%v: %s", test.args, err)
+		}
+		if config := codec.Config(); config.Salt != test.salt || config.MinLength != test.minLength {
+			t.Errorf("%v: Expected salt `%s` and min length %d but got `%+v`", test.args, test.salt, test.minLength, config)
+		}
+	}
+
+	for args, expected := range map[string]string{
+		"-profile users":                    "-profile requires -config",
+		"-config " + config:                 "-profile is required to choose one of orders, users",
+		"-config " + config + " -profile x": `unknown profile "x", expected one of orders, users`,
+	} {
+		if _, err := parseCodecOptions(strings.Fields(args)...); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: Expected error `%s` but got `%v`", args, expected, err)
+		}
+	}
+}
+
+// TestCodecOptionsEnvNames checks that the variables of the command are not rejected by hashids.ParseEnv
+func TestCodecOptionsEnvNames(t *testing.T) {
+	environ := []string{envSalt + "=s", envAlphabet + "=a", envMinLength + "=1", envExactLength + "=2", "HASHID_USERS_SALT=users"}
+	r, err := hashids.LoadRegistryEnv("HASHID_", environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := r.Names(); len(names) != 1 || names[0] != "users" {
+		t.Errorf("Expected the codec `users` only but got `%v`", names)
+	}
+}
+
+func TestItemsStream(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	convert := func(input string) record { return encodeIntList(codec, input, ",") }
+	one, two := encodeTest(t, codec, 1), encodeTest(t, codec, 2)
+
+	tests := []struct {
+		name        string
+		opts        itemOptions
+		args        []string
+		in          string
+		out, errOut string
+		status      int
+	}{
+		{
+			name: "arguments",
+			opts: itemOptions{format: "text"},
+			args: []string{"1", "2"},
+			in:   "3\n",
+			out:  "1: " + one + "\n2: " + two + "\n",
+		},
+		{
+			name: "stdin flag skips blank lines",
+			opts: itemOptions{format: "text", stdin: true},
+			in:   "1\n\n  2  \r\n",
+			out:  "1: " + one + "\n2: " + two + "\n",
+		},
+		{
+			name: "single dash reads stdin",
+			opts: itemOptions{format: "text"},
+			args: []string{"-"},
+			in:   "1\n2",
+			out:  "1: " + one + "\n2: " + two + "\n",
+		},
+		{
+			name:   "errors do not stop",
+			opts:   itemOptions{format: "text", stdin: true},
+			in:     "1\nx\n2\n",
+			out:    "1: " + one + "\n2: " + two + "\n",
+			errOut: "x: strconv.ParseInt: parsing \"x\": invalid syntax\n",
+			status: 1,
+		},
+		{
+			name:   "fail fast stops at the first error",
+			opts:   itemOptions{format: "text", stdin: true, failFast: true},
+			in:     "1\nx\n2\n",
+			out:    "1: " + one + "\n",
+			errOut: "x: strconv.ParseInt: parsing \"x\": invalid syntax\n",
+			status: 1,
+		},
+		{
+			name:   "fail fast with arguments",
+			opts:   itemOptions{format: "text", failFast: true},
+			args:   []string{"x", "1"},
+			errOut: "x: strconv.ParseInt: parsing \"x\": invalid syntax\n",
+			status: 1,
+		},
+	}
+
+	for _, test := range tests {
+		var out, errOut bytes.Buffer
+		status := test.opts.stream(test.args, strings.NewReader(test.in), &out, &errOut, convert)
+		if status != test.status {
+			t.Errorf("%s: Expected status %d but got %d", test.name, test.status, status)
+		}
+		if out.String() != test.out {
+			t.Errorf("%s: Expected output %q but got %q", test.name, test.out, out.String())
+		}
+		if errOut.String() != test.errOut {
+			t.Errorf("%s: Expected errors %q but got %q", test.name, test.errOut, errOut.String())
+		}
+	}
+}
+
+func TestModes(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	tests := []struct {
+		opts  modeOptions
+		input string
+	}{
+		{modeOptions{separator: ","}, "1,2,3"},
+		{modeOptions{separator: " "}, "1 2 3"},
+		{modeOptions{separator: ",", hex: true}, "c0ffee"},
+		{modeOptions{separator: ",", uuid: true}, "123e4567-e89b-12d3-a456-426614174000"},
+	}
+	for _, test := range tests {
+		encode, err := test.opts.encoder(codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decode, err := test.opts.decoder(codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded := encode(test.input)
+		if encoded.Err != nil {
+			t.Errorf("%s: %s", test.input, encoded.Err)
+			continue
+		}
+		if decoded := decode(encoded.Text); decoded.Err != nil || decoded.Text != test.input {
+			t.Errorf("Expected `%s` but got `%s` (%v)", test.input, decoded.Text, decoded.Err)
+		}
+	}
+
+	auto := modeOptions{separator: ",", auto: true}
+	encode, _ := auto.encoder(codec)
+	for input, expected := range map[string]record{
+		"12":                                   encodeIntList(codec, "12", ","),
+		"c0ffee":                               encodeHex(codec, "c0ffee"),
+		"123e4567-e89b-12d3-a456-426614174000": encodeUUID(codec, "123e4567-e89b-12d3-a456-426614174000"),
+	} {
+		if r := encode(input); r.Text != expected.Text {
+			t.Errorf("Expected -auto to encode `%s` to `%s` but got `%s`", input, expected.Text, r.Text)
+		}
+	}
+	if _, err := auto.decoder(codec); err == nil {
+		t.Error("Expected an error decoding with -auto")
+	}
+	if _, err := (&modeOptions{hex: true, uuid: true}).encoder(codec); err == nil {
+		t.Error("Expected an error with -hex and -uuid")
+	}
+}
+
+func TestIsIntList(t *testing.T) {
+	for s, expected := range map[string]bool{
+		"1":                    true,
+		"1,-2,0x10":            true,
+		"99999999999999999999": true,
+		"1,,2":                 false,
+		"abc":                  false,
+		"":                     false,
+	} {
+		if isIntList(s, ",") != expected {
+			t.Errorf("Expected isIntList(%q) to be %v", s, expected)
+		}
+	}
+}
+
+func TestDecodeUUIDWithErrors(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	for _, hash := range []string{encodeTest(t, codec, 1, 2), encodeTest(t, codec, 1, 2, 256)} {
+		if r := decodeUUID(codec, hash); r.Err == nil {
+			t.Errorf("Expected an error decoding `%s` to a UUID but got `%s`", hash, r.Text)
+		}
+	}
+	if r := encodeUUID(codec, "123e4567e89b12d3a456426614174000"); r.Err == nil {
+		t.Error("Expected an error encoding a UUID without dashes")
+	}
+}
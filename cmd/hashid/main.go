@@ -3,74 +3,213 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
-
-	"github.com/speps/go-hashids/v2"
 )
 
+// command is a subcommand of hashid, eg. encode or hex decode
+type command struct {
+	name string
+	// args describes the arguments after the options
+	args  string
+	short string
+	long  string
+	// choices are the values of the arguments, for shell completion
+	choices []string
+	// setup adds the flags of the command to fs and returns the function running it with the remaining arguments,
+	// which returns the exit status
+	setup func(fs *flag.FlagSet) func(args []string) int
+}
+
+var program = filepath.Base(os.Args[0])
+
+// commands is set in init as the help command refers to it
+var commands []*command
+
+func init() {
+	commands = []*command{
+		encodeCommand,
+		decodeCommand,
+		hexEncodeCommand,
+		hexDecodeCommand,
+		inspectCommand,
+		verifyCommand,
+		infoCommand,
+		csvCommand,
+		serveCommand,
+		replCommand,
+		migrateCommand,
+		completionCommand,
+		manCommand,
+		helpCommand,
+	}
+	helpCommand.choices = commandWords(0)
+}
+
 func main() {
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr,
-			"usage:\n"+
-				"\t%s [options] <intlist> [<intlist>...]\n"+
-				"\t%s [options] -d <hashid> [<hashid>...]\n\n", os.Args[0], os.Args[0])
-		flag.PrintDefaults()
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the command named by the first arguments, or the flags without a command
+// for backward compatibility, and returns the exit status
+func run(args []string) int {
+	if cmd, rest := findCommand(args); cmd != nil {
+		return cmd.run(rest)
+	}
+	return legacyCommand.run(args)
+}
+
+// findCommand returns the command named by the first arguments and the arguments left
+func findCommand(args []string) (*command, []string) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		match := true
+		for i, word := range words {
+			if args[i] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return cmd, args[len(words):]
+		}
 	}
+	return nil, args
+}
 
-	var decode bool
-	var params hashids.HashIDData
-	var separator string
-	flag.StringVar(&params.Salt, `salt`, "", `salt`)
-	flag.StringVar(&params.Alphabet, `alphabet`, hashids.DefaultAlphabet, `minimum 16 characters`)
-	flag.IntVar(&params.MinLength, `min`, 0, `minimum length (for encoding)`)
-	flag.BoolVar(&decode, `d`, false, `decode (instead of encoding)`)
-	flag.StringVar(&separator, `sep`, ",", `separator for integers`)
-	flag.Parse()
-
-	codec, err := hashids.NewWithData(&params)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+// commandWords returns the distinct words at index i of the command names, in order
+func commandWords(i int) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, cmd := range commands {
+		if fields := strings.Fields(cmd.name); i < len(fields) && !seen[fields[i]] {
+			seen[fields[i]] = true
+			words = append(words, fields[i])
+		}
 	}
+	return words
+}
 
-	args := os.Args[len(os.Args)-flag.NArg():]
-	if decode {
-		for _, arg := range args {
-			result, err := codec.DecodeInt64WithError(arg)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %s\n", arg, err)
-			} else {
-				var str []byte
-				for _, x := range result {
-					if len(str) != 0 {
-						str = append(str, separator...)
-					}
-					str = strconv.AppendInt(str, x, 10)
-				}
-				fmt.Printf("%s: %s\n", arg, str)
+func (c *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		c.printUsage(fs)
+	}
+	return fs
+}
+
+// flags returns the flags of the command, sorted by name
+func (c *command) flags() []*flag.Flag {
+	fs := c.flagSet()
+	c.setup(fs)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+func (c *command) run(args []string) int {
+	fs := c.flagSet()
+	runner := c.setup(fs)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	return runner(fs.Args())
+}
+
+func (c *command) printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	if c == legacyCommand {
+		printUsage(w)
+		fmt.Fprintf(w, "\n%s\n\noptions without a command:\n", codecOptionsUsage)
+		fs.PrintDefaults()
+		return
+	}
+	fmt.Fprintf(w, "usage:\n\t%s %s [options] %s\n\n%s\n", program, c.name, c.args, c.short)
+	if c.long != "" {
+		fmt.Fprintf(w, "\n%s\n", c.long)
+	}
+	if fs.Lookup("config") != nil {
+		fmt.Fprintf(w, "\n%s\n", codecOptionsUsage)
+	}
+	fmt.Fprintf(w, "\noptions:\n")
+	fs.PrintDefaults()
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w,
+		"usage:\n"+
+			"\t%s <command> [options] [arguments]\n"+
+			"\t%s [options] [-d] <intlist|hashid>... (same as encode or decode)\n\n"+
+			"commands:\n", program, program)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\t%-12s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the options of a command.\n", program)
+}
+
+var helpCommand = &command{
+	name:  "help",
+	args:  "[command]",
+	short: "show the usage of a command",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		return func(args []string) int {
+			if len(args) == 0 {
+				printUsage(os.Stdout)
+				return 0
+			}
+			cmd, rest := findCommand(args)
+			if cmd == nil || len(rest) > 0 {
+				fmt.Fprintf(os.Stderr, "unknown command %q\n", strings.Join(args, " "))
+				return 2
 			}
+			cmdFlags := cmd.flagSet()
+			cmdFlags.SetOutput(os.Stdout)
+			cmd.setup(cmdFlags)
+			cmdFlags.Usage()
+			return 0
 		}
-	} else {
-	ARGS:
-		for _, arg := range args {
-			spl := strings.Split(arg, separator)
-			ints := make([]int64, len(spl))
-			var err error
-			for i, s := range spl {
-				ints[i], err = strconv.ParseInt(s, 0, 64)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "%s: %s\n", arg, err)
-					continue ARGS
-				}
+	},
+}
+
+// legacyCommand runs the flags of the original command line, without a command
+var legacyCommand = &command{
+	args:  "[-d] <intlist|hashid>...|-",
+	short: "encode or decode, like the encode and decode commands",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var itemOpts itemOptions
+		var modeOpts modeOptions
+		var decode bool
+		codecOpts.addFlags(fs)
+		itemOpts.addFlags(fs)
+		modeOpts.addFlags(fs, true)
+		fs.BoolVar(&decode, `d`, false, `decode (instead of encoding)`)
+		return func(args []string) int {
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
 			}
-			result, err := codec.EncodeInt64(ints)
+			conversion := modeOpts.encoder
+			if decode {
+				conversion = modeOpts.decoder
+			}
+			convert, err := conversion(codec)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %s\n", arg, err)
-			} else {
-				fmt.Printf("%s: %v\n", arg, result)
+				fmt.Fprintln(os.Stderr, err)
+				return 2
 			}
+			return itemOpts.run(args, convert)
 		}
-	}
+	},
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		cmd  *command
+		rest []string
+	}{
+		{[]string{"encode", "1,2"}, encodeCommand, []string{"1,2"}},
+		{[]string{"hex", "decode", "-salt", "s", "x"}, hexDecodeCommand, []string{"-salt", "s", "x"}},
+		{[]string{"hex"}, nil, []string{"hex"}},
+		{[]string{"-d", "abc"}, nil, []string{"-d", "abc"}},
+	}
+	for _, test := range tests {
+		cmd, rest := findCommand(test.args)
+		if cmd != test.cmd || !reflect.DeepEqual(rest, test.rest) {
+			t.Errorf("%v: Expected %v and %v but got %v and %v", test.args, test.cmd, test.rest, cmd, rest)
+		}
+	}
+	if words := commandWords(1); !reflect.DeepEqual(words, []string{"encode", "decode"}) {
+		t.Errorf("Expected the second words `[encode decode]` but got `%v`", words)
+	}
+}
+
+func TestCompletionAndMan(t *testing.T) {
+	writers := map[string]func(*bytes.Buffer){
+		"bash": func(b *bytes.Buffer) { writeBashCompletion(b) },
+		"zsh":  func(b *bytes.Buffer) { writeZshCompletion(b) },
+		"fish": func(b *bytes.Buffer) { writeFishCompletion(b) },
+		"man":  func(b *bytes.Buffer) { writeMan(b) },
+	}
+	for name, write := range writers {
+		var b bytes.Buffer
+		write(&b)
+		for _, cmd := range commands {
+			for _, word := range strings.Fields(cmd.name) {
+				if !strings.Contains(b.String(), word) {
+					t.Errorf("%s: Expected the command `%s`", name, cmd.name)
+				}
+			}
+		}
+		for _, flag := range []string{"salt-file", "encode-cols", "max-batch"} {
+			if name == "man" {
+				flag = roffEscape(flag)
+			}
+			if !strings.Contains(b.String(), flag) {
+				t.Errorf("%s: Expected the flag -%s", name, flag)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var manCommand = &command{
+	name:  "man",
+	short: "print the man page",
+	long: "eg. to install it:\n" +
+		"\thashid man > /usr/local/share/man/man1/hashid.1",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		return func(args []string) int {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "man takes no arguments")
+				return 2
+			}
+			out := bufio.NewWriter(os.Stdout)
+			writeMan(out)
+			if err := out.Flush(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	},
+}
+
+// roffEscape escapes text for roff, it must not start a line with a request
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimLeft(line, "\t")
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = `\&` + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n.br\n")
+}
+
+func writeMan(w io.Writer) {
+	name := strings.ToUpper(program)
+	fmt.Fprintf(w, ".\\\" generated by '%s man'\n", program)
+	fmt.Fprintf(w, ".TH %s 1\n", name)
+	fmt.Fprintf(w, ".SH NAME\n%s \\- encode and decode hashids\n", roffEscape(program))
+
+	fmt.Fprint(w, ".SH SYNOPSIS\n")
+	fmt.Fprintf(w, ".B %s\n.I command\n[\\fIoptions\\fR] [\\fIarguments\\fR]\n.br\n", roffEscape(program))
+	fmt.Fprintf(w, ".B %s\n[\\fIoptions\\fR] %s\n", roffEscape(program), roffEscape(legacyCommand.args))
+
+	fmt.Fprint(w, ".SH DESCRIPTION\n")
+	fmt.Fprintf(w, "Without a command, %s %s.\n", roffEscape(program), roffEscape(legacyCommand.short))
+	fmt.Fprintf(w, ".PP\n%s\n", roffEscape(codecOptionsUsage))
+	writeManFlags(w, legacyCommand)
+
+	fmt.Fprint(w, ".SH COMMANDS\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, ".SS %s\n", roffEscape(cmd.name))
+		fmt.Fprintf(w, ".B %s %s\n[\\fIoptions\\fR] %s\n", roffEscape(program), roffEscape(cmd.name), roffEscape(cmd.args))
+		fmt.Fprintf(w, ".PP\n%s.\n", roffEscape(strings.ToUpper(cmd.short[:1])+cmd.short[1:]))
+		if cmd.long != "" {
+			fmt.Fprintf(w, ".PP\n%s\n", roffEscape(cmd.long))
+		}
+		writeManFlags(w, cmd)
+	}
+
+	fmt.Fprint(w, ".SH ENVIRONMENT\n")
+	for _, env := range []string{envSalt, envAlphabet, envMinLength, envExactLength} {
+		fmt.Fprintf(w, ".TP\n.B %s\n", roffEscape(env))
+		fmt.Fprintf(w, "Default of the \\fB\\-%s\\fR option.\n", map[string]string{
+			envSalt: "salt", envAlphabet: "alphabet", envMinLength: "min", envExactLength: "exact",
+		}[env])
+	}
+
+	fmt.Fprint(w, ".SH EXIT STATUS\n")
+	fmt.Fprint(w, ".TP\n.B 0\nSuccess.\n.TP\n.B 1\nAn item could not be converted, or the command failed.\n")
+	fmt.Fprint(w, ".TP\n.B 2\nThe options or arguments are invalid.\n")
+}
+
+func writeManFlags(w io.Writer, cmd *command) {
+	for _, f := range cmd.flags() {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, ".TP\n.B \\-%s", roffEscape(f.Name))
+		if !isBoolFlag(f) {
+			fmt.Fprintf(w, " \\fI%s\\fR", roffEscape(name))
+		}
+		fmt.Fprintf(w, "\n%s", roffEscape(usage))
+		if !isBoolFlag(f) && f.DefValue != "" && f.DefValue != "0" {
+			fmt.Fprintf(w, " (default %s)", roffEscape(f.DefValue))
+		}
+		fmt.Fprint(w, "\n")
+	}
+}
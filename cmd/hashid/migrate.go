@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+var migrateCommand = &command{
+	name:  "migrate",
+	args:  "-from-config=<old> [-from-profile=<name>] -to-config=<new> [-to-profile=<name>] [-cols=<cols>] < input > output",
+	short: "re-encode hashids from an old codec to a new one",
+	long: "The codecs are profiles of JSON, YAML or TOML config files like the -config of the other commands,\n" +
+		"the profile is optional if the file has a single one. -to-config defaults to -from-config.\n" +
+		"Reads one hashid per line from stdin, or the hashids in the columns of a CSV or TSV stream with -cols.\n" +
+		"The numbers of items unchanged, converted and failed are reported to stderr.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var from, to codecOptions
+		var cols string
+		var opts csvOptions
+		fs.StringVar(&from.configFile, `from-config`, "", `config file with the codec the hashids were encoded with`)
+		fs.StringVar(&from.profile, `from-profile`, "", `profile of the -from-config file`)
+		fs.StringVar(&to.configFile, `to-config`, "", `config file with the codec to encode the hashids with`)
+		fs.StringVar(&to.profile, `to-profile`, "", `profile of the -to-config file`)
+		fs.StringVar(&cols, `cols`, "", `comma separated columns of hashids, reads CSV instead of lines`)
+		opts.addFlags(fs)
+		return func(args []string) int {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "migrate takes no arguments, the hashids are read from stdin")
+				return 2
+			}
+			if to.configFile == "" {
+				to.configFile = from.configFile
+			}
+			if from.configFile == "" {
+				fmt.Fprintln(os.Stderr, "-from-config is required")
+				return 2
+			}
+			if err := checkOnError(opts.onError); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			var m migration
+			var err error
+			if m.from, err = from.profileCodec(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if m.to, err = to.profileCodec(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+
+			var status int
+			if cols != "" {
+				status = runCSV(&opts, []columnConversion{{cols, m.convert}})
+			} else {
+				status = m.runLines(os.Stdin, os.Stdout, os.Stderr, opts.onError)
+			}
+			fmt.Fprintf(os.Stderr, "unchanged: %d, converted: %d, failed: %d\n", m.unchanged, m.converted, m.failed)
+			return status
+		}
+	},
+}
+
+// profileCodec creates the codec of the selected profile in the config file, without flags or environment variables
+func (o *codecOptions) profileCodec() (*hashids.HashID, error) {
+	params, err := o.configProfile()
+	if err != nil {
+		return nil, err
+	}
+	codec, err := hashids.NewWithData(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", o.configFile, err)
+	}
+	return codec, nil
+}
+
+// migration re-encodes hashids and counts the results
+type migration struct {
+	from, to *hashids.HashID
+
+	unchanged, converted, failed int
+}
+
+// convert decodes hash with the old codec and encodes the numbers with the new one
+func (m *migration) convert(hash string) record {
+	r := record{Input: hash}
+	numbers, err := m.from.DecodeInt64WithError(hash)
+	if err == nil {
+		r.Text, err = m.to.EncodeInt64(numbers)
+	}
+	switch {
+	case err != nil:
+		r.Err = err
+		m.failed++
+	case r.Text == hash:
+		m.unchanged++
+	default:
+		m.converted++
+	}
+	return r
+}
+
+// runLines migrates each line of in to out and returns the exit status.
+// Blank lines are written back unchanged and not counted, so that the output lines match the input lines.
+// Lines which fail are reported to errOut and handled according to onError.
+func (m *migration) runLines(in io.Reader, w, errOut io.Writer, onError string) int {
+	out := bufio.NewWriter(w)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var err error
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			fmt.Fprintln(out, scanner.Text())
+			continue
+		}
+		r := m.convert(line)
+		if r.Err == nil {
+			fmt.Fprintln(out, r.Text)
+			continue
+		}
+		// Keep errors in order with the results
+		out.Flush()
+		fmt.Fprintf(errOut, "%s: %s\n", line, r.Err)
+		if onError == onErrorBlank {
+			fmt.Fprintln(out)
+		} else if onError == onErrorAbort {
+			err = errStopped
+			break
+		}
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil && err != errStopped {
+		fmt.Fprintln(errOut, err)
+	}
+	if err != nil || m.failed > 0 {
+		return 1
+	}
+	return 0
+}
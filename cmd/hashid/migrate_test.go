@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigration(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "hashid.yaml")
+	os.WriteFile(config, []byte("old:\n  salt: old salt\nnew:\n  salt: new salt\n  min_length: 8\n"), 0o644)
+
+	var m migration
+	var err error
+	if m.from, err = (&codecOptions{configFile: config, profile: "old"}).profileCodec(); err != nil {
+		t.Fatal(err)
+	}
+	if m.to, err = (&codecOptions{configFile: config, profile: "new"}).profileCodec(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&codecOptions{configFile: config}).profileCodec(); err == nil || !strings.Contains(err.Error(), "-profile is required") {
+		t.Errorf("Expected an error without a profile but got `%v`", err)
+	}
+
+	old1, old2 := encodeTest(t, m.from, 1), encodeTest(t, m.from, 2, 3)
+	new1, new2 := encodeTest(t, m.to, 1), encodeTest(t, m.to, 2, 3)
+	if r := m.convert(old1); r.Err != nil || r.Text != new1 {
+		t.Errorf("Expected `%s` but got `%s` (%v)", new1, r.Text, r.Err)
+	}
+
+	var out, errOut bytes.Buffer
+	in := strings.Join([]string{old2, "", "not a hash", "  ", new1}, "\n")
+	if status := m.runLines(strings.NewReader(in), &out, &errOut, onErrorBlank); status != 1 {
+		t.Errorf("Expected exit status 1 but got %d", status)
+	}
+	if expected := new2 + "\n\n\n  \n\n"; out.String() != expected {
+		t.Errorf("Expected output %q with a line for each input line but got %q", expected, out.String())
+	}
+	if !strings.HasPrefix(errOut.String(), "not a hash: ") {
+		t.Errorf("Expected an error for `not a hash` but got %q", errOut.String())
+	}
+	// new1 has the min length of the new codec and does not decode with the old one
+	if m.converted != 2 || m.failed != 2 || m.unchanged != 0 {
+		t.Errorf("Expected 2 converted, 2 failed but got %d converted, %d failed, %d unchanged", m.converted, m.failed, m.unchanged)
+	}
+
+	same := migration{from: m.to, to: m.to}
+	if r := same.convert(new1); r.Text != new1 || same.unchanged != 1 {
+		t.Errorf("Expected `%s` to be unchanged but got `%s` and %d unchanged", new1, r.Text, same.unchanged)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// record is the result of converting one input
+type record struct {
+	Input string
+	// Text is the result for the text and CSV formats
+	Text string
+	// Value is the result for the JSON formats
+	Value interface{}
+	Err   error
+}
+
+// recordWriter writes records in an output format
+type recordWriter interface {
+	write(r record) error
+	// close ends the output and flushes it
+	close() error
+}
+
+var formats = []string{"text", "json", "jsonl", "csv", "tsv"}
+
+func newRecordWriter(format string, out, errOut io.Writer) (recordWriter, error) {
+	buffered := bufio.NewWriter(out)
+	switch format {
+	case "text":
+		return &textWriter{out: buffered, errOut: errOut}, nil
+	case "json":
+		return &jsonWriter{out: buffered, array: true}, nil
+	case "jsonl":
+		return &jsonWriter{out: buffered}, nil
+	case "csv", "tsv":
+		w := csv.NewWriter(buffered)
+		if format == "tsv" {
+			w.Comma = '\t'
+		}
+		return &csvWriter{out: buffered, w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, expected one of %v", format, formats)
+}
+
+// textWriter writes results as "input: result" lines and errors to errOut
+type textWriter struct {
+	out    *bufio.Writer
+	errOut io.Writer
+}
+
+func (w *textWriter) write(r record) error {
+	if r.Err != nil {
+		// Keep errors in order with the results
+		if err := w.out.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w.errOut, "%s: %s\n", r.Input, r.Err)
+		return err
+	}
+	format := "%s: %s\n"
+	if strings.HasPrefix(r.Text, "\n") {
+		// Multi-line results start on the next line
+		format = "%s:%s\n"
+	}
+	_, err := fmt.Fprintf(w.out, format, r.Input, r.Text)
+	return err
+}
+
+func (w *textWriter) close() error {
+	return w.out.Flush()
+}
+
+// jsonRecord is the JSON form of a record
+type jsonRecord struct {
+	Input  string      `json:"input"`
+	Result interface{} `json:"result"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// jsonWriter writes records as a JSON array or as JSON lines
+type jsonWriter struct {
+	out   *bufio.Writer
+	array bool
+	count int
+}
+
+func (w *jsonWriter) write(r record) error {
+	jr := jsonRecord{Input: r.Input, Result: r.Value}
+	if r.Err != nil {
+		jr.Error = r.Err.Error()
+	}
+	b, err := json.Marshal(jr)
+	if err != nil {
+		return err
+	}
+	if w.array {
+		if w.count == 0 {
+			w.out.WriteString("[\n")
+		} else {
+			w.out.WriteString(",\n")
+		}
+	}
+	w.count++
+	w.out.Write(b)
+	if !w.array {
+		w.out.WriteByte('\n')
+	}
+	return nil
+}
+
+func (w *jsonWriter) close() error {
+	if w.array {
+		if w.count == 0 {
+			w.out.WriteString("[")
+		}
+		w.out.WriteString("\n]\n")
+	}
+	return w.out.Flush()
+}
+
+// csvWriter writes records as CSV or TSV with a header
+type csvWriter struct {
+	out    *bufio.Writer
+	w      *csv.Writer
+	header bool
+}
+
+func (w *csvWriter) write(r record) error {
+	if !w.header {
+		w.header = true
+		if err := w.w.Write([]string{"input", "result", "error"}); err != nil {
+			return err
+		}
+	}
+	var errText string
+	if r.Err != nil {
+		errText = r.Err.Error()
+	}
+	return w.w.Write([]string{r.Input, r.Text, errText})
+}
+
+func (w *csvWriter) close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecordWriters(t *testing.T) {
+	records := []record{
+		{Input: "1,2", Text: "abc", Value: "abc"},
+		{Input: "x,y", Err: errors.New("invalid")},
+	}
+	tests := []struct {
+		format, out, errOut string
+	}{
+		{"text", "1,2: abc\n", "x,y: invalid\n"},
+		{"json", "[\n{\"input\":\"1,2\",\"result\":\"abc\"},\n{\"input\":\"x,y\",\"result\":null,\"error\":\"invalid\"}\n]\n", ""},
+		{"jsonl", "{\"input\":\"1,2\",\"result\":\"abc\"}\n{\"input\":\"x,y\",\"result\":null,\"error\":\"invalid\"}\n", ""},
+		{"csv", "input,result,error\n\"1,2\",abc,\n\"x,y\",,invalid\n", ""},
+		{"tsv", "input\tresult\terror\n1,2\tabc\t\nx,y\t\tinvalid\n", ""},
+	}
+	for _, test := range tests {
+		var out, errOut bytes.Buffer
+		w, err := newRecordWriter(test.format, &out, &errOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range records {
+			if err := w.write(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.close(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != test.out || errOut.String() != test.errOut {
+			t.Errorf("%s: Expected %q and %q but got %q and %q", test.format, test.out, test.errOut, out.String(), errOut.String())
+		}
+	}
+
+	var out bytes.Buffer
+	w, _ := newRecordWriter("json", &out, &out)
+	if w.close(); out.String() != "[\n]\n" {
+		t.Errorf("Expected an empty array but got %q", out.String())
+	}
+	if _, err := newRecordWriter("xml", &out, &out); err == nil {
+		t.Error("Expected an error with an unknown format")
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	convert := func(input string) record {
+		if input == "bad" {
+			return record{Input: input, Err: errors.New("invalid")}
+		}
+		return record{Input: input, Text: input, Value: input}
+	}
+	tests := []struct {
+		format string
+		args   []string
+		status int
+	}{
+		{"json", []string{"a", "b"}, 0},
+		{"json", []string{"a", "bad"}, 1},
+		{"csv", []string{"bad"}, 1},
+		{"xml", []string{"a"}, 2},
+	}
+	for _, test := range tests {
+		var out, errOut bytes.Buffer
+		opts := itemOptions{format: test.format}
+		if status := opts.stream(test.args, strings.NewReader(""), &out, &errOut, convert); status != test.status {
+			t.Errorf("%s %v: Expected status %d but got %d", test.format, test.args, test.status, status)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+var replCommand = &command{
+	name:  "repl",
+	short: "encode and decode interactively",
+	long: "Each line is encoded if it is a list of integers, decoded otherwise.\n" +
+		"Type :help for the commands changing the codec settings.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var separator string
+		codecOpts.addFlags(fs)
+		fs.StringVar(&separator, `sep`, ",", `separator for integers`)
+		return func(args []string) int {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "repl takes no arguments")
+				return 2
+			}
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			r := &repl{codec: codec, params: codec.Config(), separator: separator, out: os.Stdout}
+			if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+				r.prompt = "> "
+			}
+			if err := r.run(os.Stdin); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	},
+}
+
+// repl keeps a codec between the lines it reads
+type repl struct {
+	codec     *hashids.HashID
+	params    hashids.HashIDData
+	separator string
+	prompt    string
+	out       io.Writer
+	// history contains the lines which were encoded or decoded, with their result
+	history []string
+	// lastHash is the last hash encoded or decoded, the default for :inspect
+	lastHash string
+}
+
+// replCommands describes the commands of the repl for :help
+var replCommands = [][2]string{
+	{":salt [salt]", "use the salt, none without argument"},
+	{":alphabet [alphabet]", "use the alphabet, the default without argument"},
+	{":min [length]", "use the minimum length, 0 without argument"},
+	{":exact [length]", "use the exact length, none without argument"},
+	{":config", "show the current settings"},
+	{":inspect [hashid]", "show how the hashid decodes, the last one without argument"},
+	{":history", "show the lines encoded or decoded"},
+	{":help", "show this help"},
+	{":quit", "exit, like end of input"},
+}
+
+func (r *repl) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, r.prompt)
+		if !scanner.Scan() {
+			if r.prompt != "" {
+				// End the prompt line
+				fmt.Fprintln(r.out)
+			}
+			return scanner.Err()
+		}
+		if !r.handle(strings.TrimSpace(scanner.Text())) {
+			return nil
+		}
+	}
+}
+
+// handle runs a line, it returns false when the repl must exit
+func (r *repl) handle(line string) bool {
+	if line == "" {
+		return true
+	}
+	if !strings.HasPrefix(line, ":") {
+		r.convert(line)
+		return true
+	}
+
+	name, arg, _ := strings.Cut(line[1:], " ")
+	arg = strings.TrimSpace(arg)
+	params := r.params
+	switch name {
+	case "salt":
+		params.Salt = arg
+	case "alphabet":
+		params.Alphabet = arg
+		if arg == "" {
+			params.Alphabet = hashids.DefaultAlphabet
+		}
+	case "min", "exact":
+		length := 0
+		if arg != "" {
+			var err error
+			if length, err = strconv.Atoi(arg); err != nil {
+				fmt.Fprintf(r.out, "error: %s\n", err)
+				return true
+			}
+		}
+		if name == "min" {
+			params.MinLength = length
+		} else {
+			params.ExactLength = length
+		}
+	case "config":
+		r.printConfig()
+		return true
+	case "inspect":
+		r.inspect(arg)
+		return true
+	case "history":
+		for i, entry := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, entry)
+		}
+		return true
+	case "help":
+		for _, cmd := range replCommands {
+			fmt.Fprintf(r.out, "%-22s %s\n", cmd[0], cmd[1])
+		}
+		fmt.Fprintln(r.out, "Other lines are encoded if they are lists of integers, decoded otherwise.")
+		return true
+	case "quit", "q", "exit":
+		return false
+	default:
+		fmt.Fprintf(r.out, "error: unknown command :%s, type :help for the commands\n", name)
+		return true
+	}
+
+	codec, err := hashids.NewWithData(&params)
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %s, keeping the previous settings\n", err)
+		return true
+	}
+	r.codec, r.params = codec, params
+	return true
+}
+
+// convert encodes line if it is a list of integers, decodes it otherwise
+func (r *repl) convert(line string) {
+	var rec record
+	if isIntList(line, r.separator) {
+		rec = encodeIntList(r.codec, line, r.separator)
+		r.lastHash = rec.Text
+	} else {
+		rec = decodeIntList(r.codec, line, r.separator)
+		r.lastHash = line
+	}
+	result := rec.Text
+	if rec.Err != nil {
+		result = "error: " + rec.Err.Error()
+	}
+	fmt.Fprintln(r.out, result)
+	r.history = append(r.history, line+" -> "+result)
+}
+
+func (r *repl) inspect(hash string) {
+	if hash == "" {
+		hash = r.lastHash
+	}
+	if hash == "" {
+		fmt.Fprintln(r.out, "error: nothing to inspect yet")
+		return
+	}
+	b, _ := r.codec.Inspect(hash)
+	fmt.Fprintf(r.out, "%s:%s\n", hash, formatBreakdown(b))
+}
+
+func (r *repl) printConfig() {
+	fmt.Fprintf(r.out, "salt: %q\n", r.params.Salt)
+	fmt.Fprintf(r.out, "alphabet: %s\n", r.params.Alphabet)
+	fmt.Fprintf(r.out, "min length: %d\n", r.params.MinLength)
+	if r.params.ExactLength > 0 {
+		fmt.Fprintf(r.out, "exact length: %d\n", r.params.ExactLength)
+	}
+	fmt.Fprintf(r.out, "fingerprint: %s\n", r.codec.Fingerprint())
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func TestRepl(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	var out bytes.Buffer
+	r := &repl{codec: codec, params: codec.Config(), separator: ",", out: &out}
+	hash := encodeTest(t, codec, 1, 2)
+	input := strings.Join([]string{
+		"1,2",
+		hash,
+		":min 10",
+		":min ten",
+		":alphabet abc",
+		":nope",
+		":history",
+		":quit",
+		"1,2",
+	}, "\n")
+	if err := r.run(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	expected := []string{
+		hash,
+		"1,2",
+		`error: strconv.Atoi: parsing "ten": invalid syntax`,
+		"error: alphabet must contain at least 16 characters, keeping the previous settings",
+		"error: unknown command :nope, type :help for the commands",
+		"   1  1,2 -> " + hash,
+		"   2  " + hash + " -> 1,2",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), out.String())
+	}
+	if r.params.MinLength != 10 || r.params.Alphabet != codec.Config().Alphabet {
+		t.Errorf("Expected min length 10 and the previous alphabet but got `%+v`", r.params)
+	}
+}
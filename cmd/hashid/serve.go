@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/speps/go-hashids/v2"
+	"github.com/speps/go-hashids/v2/hashidshttp"
+)
+
+var serveCommand = &command{
+	name:  "serve",
+	short: "serve the codec over HTTP for other programs",
+	long: "Endpoints take and return JSON with POST:\n" +
+		"\t/encode      {\"numbers\": [1, 2]} -> {\"hash\": \"...\"}\n" +
+		"\t/decode      {\"hash\": \"...\"} -> {\"numbers\": [1, 2]}\n" +
+		"\t/hex/encode  {\"hex\": \"...\"} -> {\"hash\": \"...\"}\n" +
+		"\t/hex/decode  {\"hash\": \"...\"} -> {\"hex\": \"...\"}\n" +
+		"Each endpoint has a batch version with the /batch suffix taking {\"items\": [...]} and returning\n" +
+		"{\"results\": [...]}, where each result has an \"error\" instead when its item fails.\n" +
+		"GET /healthz returns the fingerprint of the codec, GET /metrics the Prometheus metrics.",
+	setup: func(fs *flag.FlagSet) func(args []string) int {
+		var codecOpts codecOptions
+		var opts serveOptions
+		codecOpts.addFlags(fs)
+		fs.StringVar(&opts.listen, `listen`, "127.0.0.1:8080", `address to listen on`)
+		fs.Int64Var(&opts.maxBody, `max-body`, 1<<20, `maximum size of a request body in bytes`)
+		fs.IntVar(&opts.maxBatch, `max-batch`, 1000, `maximum number of items in a batch request`)
+		return func(args []string) int {
+			if len(args) > 0 {
+				fmt.Fprintln(os.Stderr, "serve takes no arguments")
+				return 2
+			}
+			codec, err := codecOpts.codec()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if err := serve(codec, &opts); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	},
+}
+
+type serveOptions struct {
+	listen   string
+	maxBody  int64
+	maxBatch int
+}
+
+// serve runs the server until it is interrupted
+func serve(codec *hashids.HashID, opts *serveOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:              opts.listen,
+		Handler:           newServer(codec, opts),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		WriteTimeout:      time.Minute,
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+	log.Printf("listening on %s", opts.listen)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// conversion converts a single item of a request, it returns the response for the item
+type conversion func(codec *hashids.HashID, item *serveItem) (*serveItem, error)
+
+// serveItem is the request and response for a single item, only the fields of the endpoint are set
+type serveItem struct {
+	Numbers []int64 `json:"numbers,omitempty"`
+	Hash    string  `json:"hash,omitempty"`
+	Hex     string  `json:"hex,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type batchRequest struct {
+	Items []*serveItem `json:"items"`
+}
+
+type batchResponse struct {
+	Results []*serveItem `json:"results"`
+}
+
+// endpoints lists the conversions, each served on its path and on its path with the /batch suffix
+var endpoints = []struct {
+	path    string
+	convert conversion
+}{
+	{"/encode", func(codec *hashids.HashID, item *serveItem) (*serveItem, error) {
+		hash, err := codec.EncodeInt64(item.Numbers)
+		return &serveItem{Hash: hash}, err
+	}},
+	{"/decode", func(codec *hashids.HashID, item *serveItem) (*serveItem, error) {
+		numbers, err := codec.DecodeInt64WithError(item.Hash)
+		return &serveItem{Numbers: numbers}, err
+	}},
+	{"/hex/encode", func(codec *hashids.HashID, item *serveItem) (*serveItem, error) {
+		hash, err := codec.EncodeHex(item.Hex)
+		return &serveItem{Hash: hash}, err
+	}},
+	{"/hex/decode", func(codec *hashids.HashID, item *serveItem) (*serveItem, error) {
+		hex, err := codec.DecodeHex(item.Hash)
+		return &serveItem{Hex: hex}, err
+	}},
+}
+
+// serverMetrics counts requests and items for each path
+type serverMetrics struct {
+	paths []string
+	// requests and items are indexed like paths, then by outcome
+	requests [][2]atomic.Int64
+	items    [][2]atomic.Int64
+}
+
+// Outcomes of the metrics
+const (
+	outcomeOK = iota
+	outcomeError
+)
+
+var outcomes = [2]string{"ok", "error"}
+
+func newServer(codec *hashids.HashID, opts *serveOptions) http.Handler {
+	mux := http.NewServeMux()
+	metrics := &serverMetrics{}
+	for _, endpoint := range endpoints {
+		metrics.paths = append(metrics.paths, endpoint.path, endpoint.path+"/batch")
+	}
+	metrics.requests = make([][2]atomic.Int64, len(metrics.paths))
+	metrics.items = make([][2]atomic.Int64, len(metrics.paths))
+
+	for i, endpoint := range endpoints {
+		mux.Handle("POST "+endpoint.path, &convertHandler{
+			codec: codec, opts: opts, convert: endpoint.convert, metrics: metrics, index: 2 * i,
+		})
+		mux.Handle("POST "+endpoint.path+"/batch", &convertHandler{
+			codec: codec, opts: opts, convert: endpoint.convert, metrics: metrics, index: 2*i + 1, batch: true,
+		})
+	}
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, struct {
+			Status      string `json:"status"`
+			Fingerprint string `json:"fingerprint"`
+		}{"ok", codec.Fingerprint()})
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		metrics.write(w)
+	})
+	return mux
+}
+
+type convertHandler struct {
+	codec   *hashids.HashID
+	opts    *serveOptions
+	convert conversion
+	metrics *serverMetrics
+	// index is the index of the path in metrics
+	index int
+	batch bool
+}
+
+func (h *convertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	outcome := h.serve(w, r)
+	h.metrics.requests[h.index][outcome].Add(1)
+}
+
+// serve handles the request and returns its outcome
+func (h *convertHandler) serve(w http.ResponseWriter, r *http.Request) int {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.opts.maxBody))
+	dec.DisallowUnknownFields()
+
+	if !h.batch {
+		var item serveItem
+		if !decodeRequest(w, dec, &item) {
+			return outcomeError
+		}
+		result, err := h.convert(h.codec, &item)
+		if err != nil {
+			h.metrics.items[h.index][outcomeError].Add(1)
+			hashidshttp.WriteError(w, http.StatusUnprocessableEntity, err.Error(), true)
+			return outcomeError
+		}
+		h.metrics.items[h.index][outcomeOK].Add(1)
+		writeJSON(w, result)
+		return outcomeOK
+	}
+
+	var batch batchRequest
+	if !decodeRequest(w, dec, &batch) {
+		return outcomeError
+	}
+	if len(batch.Items) > h.opts.maxBatch {
+		hashidshttp.WriteError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%d items exceed the maximum of %d", len(batch.Items), h.opts.maxBatch), true)
+		return outcomeError
+	}
+	response := batchResponse{Results: make([]*serveItem, len(batch.Items))}
+	for i, item := range batch.Items {
+		if item == nil {
+			item = &serveItem{}
+		}
+		result, err := h.convert(h.codec, item)
+		if err != nil {
+			h.metrics.items[h.index][outcomeError].Add(1)
+			result = &serveItem{Error: err.Error()}
+		} else {
+			h.metrics.items[h.index][outcomeOK].Add(1)
+		}
+		response.Results[i] = result
+	}
+	writeJSON(w, response)
+	return outcomeOK
+}
+
+// decodeRequest decodes the body of a request to v, it writes the error response and returns false if it fails
+func decodeRequest(w http.ResponseWriter, dec *json.Decoder, v interface{}) bool {
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the JSON value")
+	}
+	if err == nil {
+		return true
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		hashidshttp.WriteError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit), true)
+		return false
+	}
+	hashidshttp.WriteError(w, http.StatusBadRequest, err.Error(), true)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// write writes the metrics in the Prometheus text format
+func (m *serverMetrics) write(w http.ResponseWriter) {
+	counters := []struct {
+		name, help string
+		values     [][2]atomic.Int64
+	}{
+		{"hashid_requests_total", "Requests handled, by path and outcome.", m.requests},
+		{"hashid_items_total", "Items converted, by path and outcome.", m.items},
+	}
+	for _, counter := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for i, path := range m.paths {
+			for outcome, name := range outcomes {
+				fmt.Fprintf(w, "%s{path=%q,outcome=%q} %d\n", counter.name, path, name, counter.values[i][outcome].Load())
+			}
+		}
+	}
+}
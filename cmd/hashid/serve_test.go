@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// post sends body to path and decodes the JSON response to v, it returns the status code
+func post(t *testing.T, handler http.Handler, path, body string, v interface{}) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %s: %s", path, err, w.Body)
+		}
+	}
+	return w.Code
+}
+
+func TestServeEncodeDecode(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	handler := newServer(codec, &serveOptions{maxBody: 1 << 10, maxBatch: 10})
+	hash := encodeTest(t, codec, 1, 2)
+
+	var encoded serveItem
+	if code := post(t, handler, "/encode", `{"numbers": [1, 2]}`, &encoded); code != http.StatusOK || encoded.Hash != hash {
+		t.Errorf("Expected 200 and `%s` but got %d and `%+v`", hash, code, encoded)
+	}
+	var decoded serveItem
+	if code := post(t, handler, "/decode", `{"hash": "`+hash+`"}`, &decoded); code != http.StatusOK || len(decoded.Numbers) != 2 || decoded.Numbers[1] != 2 {
+		t.Errorf("Expected 200 and [1 2] but got %d and `%+v`", code, decoded)
+	}
+
+	var hex serveItem
+	post(t, handler, "/hex/encode", `{"hex": "c0ffee"}`, &hex)
+	if code := post(t, handler, "/hex/decode", `{"hash": "`+hex.Hash+`"}`, &hex); code != http.StatusOK || hex.Hex != "c0ffee" {
+		t.Errorf("Expected 200 and c0ffee but got %d and `%+v`", code, hex)
+	}
+
+	for path, body := range map[string]string{
+		"/decode": `{"hash": "not a hash"}`,
+		"/encode": `{"numbers": [-1]}`,
+	} {
+		var problem map[string]interface{}
+		if code := post(t, handler, path, body, &problem); code != http.StatusUnprocessableEntity || problem["detail"] == nil {
+			t.Errorf("%s: expected 422 with a detail but got %d and `%v`", path, code, problem)
+		}
+	}
+	for _, body := range []string{`{"numbers": [1]} {}`, `{"digits": [1]}`, `[`} {
+		if code := post(t, handler, "/encode", body, nil); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for `%s` but got %d", body, code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/encode", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /encode but got %d", w.Code)
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	handler := newServer(codec, &serveOptions{maxBody: 1 << 10, maxBatch: 3})
+
+	var response batchResponse
+	code := post(t, handler, "/encode/batch", `{"items": [{"numbers": [1]}, {"numbers": [-1]}, null]}`, &response)
+	if code != http.StatusOK || len(response.Results) != 3 {
+		t.Fatalf("Expected 200 and 3 results but got %d and `%+v`", code, response)
+	}
+	if hash := encodeTest(t, codec, 1); response.Results[0].Hash != hash || response.Results[0].Error != "" {
+		t.Errorf("Expected `%s` but got `%+v`", hash, response.Results[0])
+	}
+	for _, result := range response.Results[1:] {
+		if result.Error == "" || result.Hash != "" {
+			t.Errorf("Expected an error but got `%+v`", result)
+		}
+	}
+
+	if code := post(t, handler, "/decode/batch", `{"items": [{}, {}, {}, {}]}`, nil); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for too many items but got %d", code)
+	}
+	body := `{"numbers": [` + strings.Repeat("1, ", 1<<9) + `1]}`
+	if code := post(t, handler, "/encode", body, nil); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a body of %d bytes but got %d", len(body), code)
+	}
+}
+
+func TestServeHealthAndMetrics(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	codec, _ := hashids.NewWithData(hdata)
+	handler := newServer(codec, &serveOptions{maxBody: 1 << 10, maxBatch: 10})
+	post(t, handler, "/encode", `{"numbers": [1]}`, nil)
+	post(t, handler, "/encode", `{"numbers": [-1]}`, nil)
+	post(t, handler, "/decode/batch", `{"items": [{"hash": "x"}, {"hash": "`+encodeTest(t, codec, 1)+`"}]}`, nil)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	var health struct{ Status, Fingerprint string }
+	if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil || health.Status != "ok" || health.Fingerprint != codec.Fingerprint() {
+		t.Errorf("Expected ok and the fingerprint but got `%s`", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus content type but got `%s`", contentType)
+	}
+	for _, line := range []string{
+		"# TYPE hashid_requests_total counter",
+		`hashid_requests_total{path="/encode",outcome="ok"} 1`,
+		`hashid_requests_total{path="/encode",outcome="error"} 1`,
+		`hashid_requests_total{path="/decode/batch",outcome="ok"} 1`,
+		`hashid_items_total{path="/decode/batch",outcome="ok"} 1`,
+		`hashid_items_total{path="/decode/batch",outcome="error"} 1`,
+		`hashid_items_total{path="/hex/decode",outcome="ok"} 0`,
+	} {
+		if !strings.Contains(w.Body.String(), line+"\n") {
+			t.Errorf("Expected the line `%s` in the metrics:\n%s", line, w.Body)
+		}
+	}
+}
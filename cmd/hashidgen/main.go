@@ -0,0 +1,269 @@
+// Command hashidgen generates a distinct Go type for each kind of id, eg. UserID and OrderID,
+// each bound to its own codec. It is meant to be used with go generate:
+//
+//	//go:generate hashidgen -spec ids.json -o ids_gen.go
+//
+// The spec file contains the package name and the types with their HashIDData:
+//
+//	{
+//		"package": "models",
+//		"types": [
+//			{"name": "UserID", "codec": {"salt": "users", "min_length": 8}},
+//			{"name": "OrderID", "codec": {"salt": "orders"}, "salt_env": "ORDER_ID_SALT"}
+//		]
+//	}
+//
+// When salt_env is set, the salt is read from that environment variable at init instead,
+// so that it does not have to be committed. The program panics at init if the variable is not set.
+//
+// The generated code only declares identifiers derived from the type names, so several spec files
+// can be generated into the same package.
+//
+// Each generated type is an int64 implementing fmt.Stringer, encoding.TextMarshaler,
+// encoding.TextUnmarshaler, json.Marshaler, json.Unmarshaler, sql.Scanner and driver.Valuer.
+// Text and JSON carry the hashid while SQL stores the integer.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+type spec struct {
+	Package string     `json:"package"`
+	Types   []typeSpec `json:"types"`
+}
+
+type typeSpec struct {
+	Name    string             `json:"name"`
+	Codec   hashids.HashIDData `json:"codec"`
+	SaltEnv string             `json:"salt_env"`
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage:\n\t%s -spec <file> [-o <file>]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	var specPath, output string
+	flag.StringVar(&specPath, `spec`, "", `spec file describing the types`)
+	flag.StringVar(&output, `o`, "", `output file (default stdout)`)
+	flag.Parse()
+
+	if specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(specPath, output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, output string) error {
+	content, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	s, err := parseSpec(content)
+	if err != nil {
+		return fmt.Errorf("%s: %s", specPath, err)
+	}
+	src, err := generate(s)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+func parseSpec(content []byte) (*spec, error) {
+	s := &spec{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return nil, err
+	}
+	if s.Package == "" {
+		s.Package = os.Getenv("GOPACKAGE")
+	}
+	if !token.IsIdentifier(s.Package) {
+		return nil, fmt.Errorf("invalid package name %q", s.Package)
+	}
+	seen := make(map[string]bool)
+	for i := range s.Types {
+		t := &s.Types[i]
+		if !token.IsIdentifier(t.Name) || !token.IsExported(t.Name) {
+			return nil, fmt.Errorf("invalid type name %q", t.Name)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate type %s", t.Name)
+		}
+		seen[t.Name] = true
+		if t.Codec.Alphabet == "" {
+			t.Codec.Alphabet = hashids.DefaultAlphabet
+		}
+		// Validate the codec now rather than when the generated code is initialized
+		if _, err := hashids.NewWithData(&t.Codec); err != nil {
+			return nil, fmt.Errorf("type %s: %s", t.Name, err)
+		}
+	}
+	return s, nil
+}
+
+func generate(s *spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, s); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+var fileTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"lowerFirst": func(s string) string { return strings.ToLower(s[:1]) + s[1:] },
+}).Parse(`// Code generated by hashidgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	{{- range .Types}}{{if .SaltEnv}}
+	"os"{{break}}{{end}}{{end}}
+	"strconv"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+{{range .Types}}{{$codec := printf "%sCodec" (lowerFirst .Name)}}
+// {{.Name}} is an id encoded as a hashid in text and JSON, and stored as an integer in SQL databases
+type {{.Name}} int64
+
+var {{$codec}} = func() *hashids.HashID {
+	{{- if .SaltEnv}}
+	salt, ok := os.LookupEnv({{printf "%q" .SaltEnv}})
+	if !ok {
+		panic({{printf "%s: environment variable %s is not set" .Name .SaltEnv | printf "%q"}})
+	}
+	{{- end}}
+	h, err := hashids.NewWithData(&hashids.HashIDData{
+		Alphabet:    {{printf "%q" .Codec.Alphabet}},
+		MinLength:   {{.Codec.MinLength}},
+		ExactLength: {{.Codec.ExactLength}},
+		{{- if .SaltEnv}}
+		Salt:        salt,
+		{{- else}}
+		Salt:        {{printf "%q" .Codec.Salt}},
+		{{- end}}
+	})
+	if err != nil {
+		panic("{{.Name}}: " + err.Error())
+	}
+	return h
+}()
+
+// Parse{{.Name}} decodes a {{.Name}} from its hashid
+func Parse{{.Name}}(s string) ({{.Name}}, error) {
+	numbers, err := {{$codec}}.DecodeInt64WithError(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) != 1 {
+		return 0, fmt.Errorf("invalid {{.Name}} %q", s)
+	}
+	return {{.Name}}(numbers[0]), nil
+}
+
+// String returns the hashid of id
+func (id {{.Name}}) String() string {
+	s, err := {{$codec}}.EncodeInt64([]int64{int64(id)})
+	if err != nil {
+		return fmt.Sprintf("%%!{{.Name}}(%d)", int64(id))
+	}
+	return s
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (id {{.Name}}) MarshalText() ([]byte, error) {
+	s, err := {{$codec}}.EncodeInt64([]int64{int64(id)})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (id *{{.Name}}) UnmarshalText(text []byte) error {
+	parsed, err := Parse{{.Name}}(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (id {{.Name}}) MarshalJSON() ([]byte, error) {
+	text, err := id.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (id *{{.Name}}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return id.UnmarshalText([]byte(s))
+}
+
+// Scan implements sql.Scanner, integers may also be given as text like in the MySQL text protocol
+func (id *{{.Name}}) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case int64:
+		*id = {{.Name}}(v)
+		return nil
+	case nil:
+		*id = 0
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("cannot scan %T into {{.Name}}", src)
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into {{.Name}}: %w", text, err)
+	}
+	*id = {{.Name}}(n)
+	return nil
+}
+
+// Value implements driver.Valuer
+func (id {{.Name}}) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+{{end}}`))
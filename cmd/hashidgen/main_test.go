@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerateCompiles generates two specs into the same package and runs a program using both
+func TestGenerateCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/ids\n\ngo 1.22\n\n"+
+		"require github.com/speps/go-hashids/v2 v2.0.0\n\n"+
+		"replace github.com/speps/go-hashids/v2 => "+root+"\n")
+	specs := map[string]string{
+		"users_gen.go":  `{"package": "main", "types": [{"name": "UserID", "codec": {"salt": "users", "min_length": 8}}]}`,
+		"orders_gen.go": `{"package": "main", "types": [{"name": "OrderID", "codec": {"salt": "unused"}, "salt_env": "ORDER_ID_SALT"}]}`,
+	}
+	for name, content := range specs {
+		s, err := parseSpec([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := generate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, name), string(src))
+	}
+	writeFile(t, filepath.Join(dir, "main.go"), `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	user, order := UserID(42), OrderID(7)
+	data, err := json.Marshal(map[string]interface{}{"user": user, "order": order})
+	if err != nil {
+		panic(err)
+	}
+	var decoded struct {
+		User  UserID
+		Order OrderID
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		panic(err)
+	}
+	var scanned [3]UserID
+	for i, src := range []interface{}{int64(42), []byte("42"), "42"} {
+		if err := scanned[i].Scan(src); err != nil {
+			panic(err)
+		}
+	}
+	if err := scanned[0].Scan("4x"); err == nil {
+		panic("expected an error scanning 4x")
+	}
+	fmt.Println(len(user.String()) >= 8, decoded.User == user, decoded.Order == order, scanned == [3]UserID{user, user, user})
+}
+`)
+
+	bin := filepath.Join(dir, "ids")
+	build := exec.Command(goCmd, "build", "-mod=mod", "-o", bin, ".")
+	build.Dir = dir
+	build.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off", "GOWORK=off")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Expected the generated code to compile but got `%s`\n%s", err, out)
+	}
+
+	run := exec.Command(bin)
+	run.Env = append(os.Environ(), "ORDER_ID_SALT=orders")
+	out, err := run.CombinedOutput()
+	if err != nil || string(out) != "true true true true\n" {
+		t.Errorf("Expected `true true true true` but got `%s` (%v)", out, err)
+	}
+
+	run = exec.Command(bin)
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "ORDER_ID_SALT=") {
+			run.Env = append(run.Env, v)
+		}
+	}
+	out, err = run.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "OrderID: environment variable ORDER_ID_SALT is not set") {
+		t.Errorf("Expected a panic without ORDER_ID_SALT but got `%s` (%v)", out, err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseSpecWithUnknownCodecKey(t *testing.T) {
+	_, err := parseSpec([]byte(`{"package": "ids", "types": [{"name": "UserID", "codec": {"salt": "x", "min_lenght": 8}}]}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown field "min_lenght"`) {
+		t.Errorf("Expected an unknown field error but got `%v`", err)
+	}
+}
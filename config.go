@@ -0,0 +1,466 @@
+package hashids
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config formats supported by ParseConfig
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Config returns the HashIDData the HashID was created with
+func (h *HashID) Config() HashIDData {
+	return h.data
+}
+
+// Alphabet returns the alphabet used for numbers, shuffled and without separators and guards
+func (h *HashID) Alphabet() string {
+	return string(h.alphabet)
+}
+
+// Separators returns the runes used between numbers
+func (h *HashID) Separators() string {
+	return string(h.seps)
+}
+
+// Guards returns the runes used to pad hashes to the minimum length
+func (h *HashID) Guards() string {
+	return string(h.guards)
+}
+
+// UnmarshalJSON decodes the keys written by encoding/json like min_length, as well as the Go field names
+// like MinLength used before HashIDData had JSON tags. Keys are matched like in ParseConfig.
+// Unknown keys and a field given twice are errors, so that a misspelled setting is not silently dropped.
+func (h *HashIDData) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	seen := make(map[string]string, len(fields))
+	for key, value := range fields {
+		normalized := normalizeKey(key)
+		var dst interface{}
+		switch normalized {
+		case "alphabet":
+			dst = &h.Alphabet
+		case "minlength":
+			dst = &h.MinLength
+		case "exactlength":
+			dst = &h.ExactLength
+		case "salt":
+			dst = &h.Salt
+		default:
+			return fmt.Errorf("json: unknown field %q", key)
+		}
+		if other, found := seen[normalized]; found {
+			return fmt.Errorf("json: fields %q and %q set the same value", other, key)
+		}
+		seen[normalized] = key
+		if err := json.Unmarshal(value, dst); err != nil {
+			return fmt.Errorf("json: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Fingerprint returns a hash of the alphabet, separators and guards computed from the HashIDData
+// and of the lengths, without revealing the salt. Codecs with the same fingerprint generate and accept
+// the same hashes, even in other languages.
+//
+// It is the hex encoded SHA-256 of the alphabet, separators and guards in UTF-8, the effective minimum
+// length, which is at least the exact length, and the exact length in decimal, separated by NUL bytes.
+func (h *HashID) Fingerprint() string {
+	sum := sha256.New()
+	sum.Write([]byte(string(h.alphabet)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(string(h.seps)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(string(h.guards)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(strconv.Itoa(h.minLength)))
+	sum.Write([]byte{0})
+	sum.Write([]byte(strconv.Itoa(h.exactLength)))
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// ParseConfig reads named HashIDData from r in the given format.
+//
+// Each codec is a section named after it, containing the HashIDData fields as keys, eg. in TOML:
+//
+//	[users]
+//	salt = "users salt"
+//	min_length = 8
+//
+// Keys are case insensitive and may use underscores like min_length or be written MinLength.
+// Codecs without an alphabet use the DefaultAlphabet. Only the flat mappings needed for these
+// settings are supported in YAML and TOML.
+func ParseConfig(r io.Reader, format string) (map[string]*HashIDData, error) {
+	var sections map[string]map[string]string
+	var err error
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		sections, err = parseJSONSections(r)
+	case FormatYAML, "yml":
+		sections, err = parseYAMLSections(r)
+	case FormatTOML:
+		sections, err = parseTOMLSections(r)
+	default:
+		return nil, fmt.Errorf("unknown config format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dataFromSections(sections)
+}
+
+// ParseConfigFile reads named HashIDData from a file, its format is taken from its extension
+func ParseConfigFile(path string) (map[string]*HashIDData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(f, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ParseEnv reads named HashIDData from environment variables like os.Environ returns them.
+// Variables are named prefix, the codec name and the field, eg. HASHID_USERS_SALT or
+// HASHID_USERS_MIN_LENGTH with the prefix HASHID_. Codec names are lowercased.
+// Every variable starting with prefix must be one of these, the others are reported as errors.
+func ParseEnv(prefix string, environ []string) (map[string]*HashIDData, error) {
+	suffixes := []string{"_ALPHABET", "_MIN_LENGTH", "_EXACT_LENGTH", "_SALT"}
+	sections := make(map[string]map[string]string)
+	var errs []error
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		found := false
+		for _, suffix := range suffixes {
+			if name := strings.TrimSuffix(key[len(prefix):], suffix); name != key[len(prefix):] && name != "" {
+				name = strings.ToLower(name)
+				if sections[name] == nil {
+					sections[name] = make(map[string]string)
+				}
+				sections[name][suffix[1:]] = value
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: unknown variable, expected %s<NAME> followed by one of %s",
+				key, prefix, strings.Join(suffixes, ", ")))
+		}
+	}
+	data, err := dataFromSections(sections)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errors.Join(errs...)
+	}
+	return data, nil
+}
+
+// LoadRegistry creates a Registry from a config read by ParseConfig
+func LoadRegistry(r io.Reader, format string) (*Registry, error) {
+	data, err := ParseConfig(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistryWithData(data)
+}
+
+// LoadRegistryFile creates a Registry from a config file read by ParseConfigFile
+func LoadRegistryFile(path string) (*Registry, error) {
+	data, err := ParseConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistryWithData(data)
+}
+
+// LoadRegistryEnv creates a Registry from environment variables read by ParseEnv
+func LoadRegistryEnv(prefix string, environ []string) (*Registry, error) {
+	data, err := ParseEnv(prefix, environ)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistryWithData(data)
+}
+
+// dataFromSections creates a HashIDData for each section, reporting every invalid one
+func dataFromSections(sections map[string]map[string]string) (map[string]*HashIDData, error) {
+	result := make(map[string]*HashIDData, len(sections))
+	var errs []error
+	for name, fields := range sections {
+		data, err := dataFromFields(fields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("codec %q: %w", name, err))
+			continue
+		}
+		result[name] = data
+	}
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// dataFromFields creates a HashIDData from its fields as strings, reporting every invalid field
+func dataFromFields(fields map[string]string) (*HashIDData, error) {
+	data := NewData()
+	var errs []error
+	seen := make(map[string]string, len(fields))
+	for key, value := range fields {
+		normalized := normalizeKey(key)
+		if other, found := seen[normalized]; found {
+			first, second := other, key
+			if second < first {
+				first, second = second, first
+			}
+			errs = append(errs, fmt.Errorf("%s: same setting as %s", second, first))
+			continue
+		}
+		seen[normalized] = key
+		var err error
+		switch normalized {
+		case "alphabet":
+			data.Alphabet = value
+		case "salt":
+			data.Salt = value
+		case "minlength":
+			data.MinLength, err = strconv.Atoi(value)
+		case "exactlength":
+			data.ExactLength, err = strconv.Atoi(value)
+		default:
+			err = errors.New("unknown key")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errors.Join(errs...)
+	}
+	return data, nil
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+func sortErrors(errs []error) {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+}
+
+func parseJSONSections(r io.Reader) (map[string]map[string]string, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var raw map[string]map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	sections := make(map[string]map[string]string, len(raw))
+	var errs []error
+	for name, fields := range raw {
+		sections[name] = make(map[string]string, len(fields))
+		for key, value := range fields {
+			switch value := value.(type) {
+			case string:
+				sections[name][key] = value
+			case json.Number:
+				sections[name][key] = value.String()
+			default:
+				errs = append(errs, fmt.Errorf("codec %q: %s: expected a string or a number but got %s", name, key, jsonType(value)))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sortErrors(errs)
+		return nil, errors.Join(errs...)
+	}
+	return sections, nil
+}
+
+// jsonType names the type of a value decoded by encoding/json
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case []interface{}:
+		return "an array"
+	}
+	return "an object"
+}
+
+func parseYAMLSections(r io.Reader) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	err := scanConfigLines(r, func(line string, indented bool) error {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return errors.New("expected key: value")
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !indented {
+			if value != "" {
+				return fmt.Errorf("expected a mapping for codec %q", key)
+			}
+			name := unquote(key)
+			if _, found := sections[name]; found {
+				return fmt.Errorf("duplicate codec %q", name)
+			}
+			section = make(map[string]string)
+			sections[name] = section
+			return nil
+		}
+		if section == nil {
+			return errors.New("key outside of a codec")
+		}
+		value, err := parseConfigValue(value)
+		if err != nil {
+			return err
+		}
+		return setConfigKey(section, key, value)
+	})
+	return sections, err
+}
+
+func parseTOMLSections(r io.Reader) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	err := scanConfigLines(r, func(line string, _ bool) error {
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return errors.New("expected [codec]")
+			}
+			name := unquote(strings.TrimSpace(line[1 : len(line)-1]))
+			if _, found := sections[name]; found {
+				return fmt.Errorf("duplicate codec %q", name)
+			}
+			section = make(map[string]string)
+			sections[name] = section
+			return nil
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return errors.New("expected key = value")
+		}
+		if section == nil {
+			return errors.New("key outside of a codec")
+		}
+		value, err := parseConfigValue(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		return setConfigKey(section, strings.TrimSpace(key), value)
+	})
+	return sections, err
+}
+
+// scanConfigLines calls fn with each line which is not empty or a comment
+func scanConfigLines(r io.Reader, fn func(line string, indented bool) error) error {
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		if err := fn(trimmed, indented); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// setConfigKey sets key in section, a key given twice is an error
+func setConfigKey(section map[string]string, key, value string) error {
+	if _, found := section[key]; found {
+		return fmt.Errorf("duplicate key %q", key)
+	}
+	section[key] = value
+	return nil
+}
+
+// parseConfigValue parses a quoted or bare value, bare values end at a comment starting with " #".
+// Only a comment can follow a quoted value. Multi-line strings and block scalars are not supported.
+func parseConfigValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''") {
+		return "", errors.New("multi-line strings are not supported")
+	}
+	var end int
+	switch value[0] {
+	case '"':
+		end = closingQuote(value)
+	case '\'':
+		if end = strings.IndexByte(value[1:], '\''); end != -1 {
+			end++
+		}
+	case '|', '>':
+		return "", errors.New("block scalars are not supported")
+	default:
+		if i := strings.Index(value, " #"); i != -1 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+	if end == -1 {
+		return "", errors.New("unterminated string")
+	}
+	if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != '#' {
+		return "", fmt.Errorf("unexpected %q after the closing quote", rest)
+	}
+	if value[0] == '\'' {
+		return value[1:end], nil
+	}
+	return strconv.Unquote(value[:end+1])
+}
+
+// closingQuote returns the index of the quote closing the double quoted string at the start of s
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package hashids
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestHashIDDataJSON(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hdata.MinLength = 30
+
+	encoded, err := json.Marshal(hdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"alphabet":"` + DefaultAlphabet + `","min_length":30,"salt":"this is my salt"}`
+	if string(encoded) != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, encoded)
+	}
+
+	var decoded HashIDData
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != *hdata {
+		t.Errorf("Expected `%v` but got `%v`", *hdata, decoded)
+	}
+
+	hid, _ := NewWithData(hdata)
+	if hid.Config() != *hdata {
+		t.Errorf("Expected config `%v` but got `%v`", *hdata, hid.Config())
+	}
+}
+
+func TestHashIDDataJSONFieldNames(t *testing.T) {
+	var decoded HashIDData
+	if err := json.Unmarshal([]byte(`{"Alphabet":"`+DefaultAlphabet+`","MinLength":20,"ExactLength":0,"Salt":"s"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected := HashIDData{Alphabet: DefaultAlphabet, MinLength: 20, Salt: "s"}
+	if decoded != expected {
+		t.Errorf("Expected `%v` but got `%v`", expected, decoded)
+	}
+
+	err := json.Unmarshal([]byte(`{"MinLength":20,"min_length":30}`), &decoded)
+	if err == nil || !strings.Contains(err.Error(), "set the same value") {
+		t.Errorf("Expected error for a field given twice but got `%v`", err)
+	}
+	if err := json.Unmarshal([]byte(`{"salt":"s","min_lenght":8}`), &decoded); err == nil || err.Error() != `json: unknown field "min_lenght"` {
+		t.Errorf("Expected error for an unknown field but got `%v`", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	same, _ := NewWithData(hdata)
+
+	hdata.MinLength = 20
+	longer, _ := NewWithData(hdata)
+	hdata.MinLength = 0
+	hdata.ExactLength = 20
+	exact, _ := NewWithData(hdata)
+	hdata.ExactLength = 0
+
+	hdata.Salt = "this is my pepper"
+	other, _ := NewWithData(hdata)
+
+	runes := hid.Alphabet() + hid.Separators() + hid.Guards()
+	if len(runes) != len(DefaultAlphabet) {
+		t.Errorf("Expected alphabet, separators and guards to contain the whole alphabet but got `%s`", runes)
+	}
+	if len(hid.Fingerprint()) != 64 {
+		t.Errorf("Expected a SHA-256 fingerprint but got `%s`", hid.Fingerprint())
+	}
+	if hid.Fingerprint() != same.Fingerprint() {
+		t.Errorf("Expected fingerprint `%s` but got `%s`", hid.Fingerprint(), same.Fingerprint())
+	}
+	if hid.Fingerprint() == other.Fingerprint() {
+		t.Errorf("Expected fingerprints to differ with another salt")
+	}
+	if hid.Fingerprint() == longer.Fingerprint() || longer.Fingerprint() == exact.Fingerprint() {
+		t.Errorf("Expected fingerprints to differ with another min length or exact length")
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/speps/go-hashids/v2
 
-go 1.14
+go 1.22
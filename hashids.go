@@ -29,9 +29,12 @@ var sepsOriginal = []rune("cfhistuCFHISTU")
 
 // HashID contains everything needed to encode/decode hashids
 type HashID struct {
+	data               HashIDData
 	alphabet           []rune
 	minLength          int
+	exactLength        int
 	maxLengthPerNumber int
+	maxExactValue      int64
 	salt               []rune
 	seps               []rune
 	guards             []rune
@@ -40,13 +43,17 @@ type HashID struct {
 // HashIDData contains the information needed to generate hashids
 type HashIDData struct {
 	// Alphabet is the alphabet used to generate new ids
-	Alphabet string
+	Alphabet string `json:"alphabet"`
 
 	// MinLength is the minimum length of a generated id
-	MinLength int
+	MinLength int `json:"min_length,omitempty"`
+
+	// ExactLength, when non-zero, is the exact length of every generated id.
+	// Shorter ids are padded like with MinLength, longer ones fail to encode.
+	ExactLength int `json:"exact_length,omitempty"`
 
 	// Salt is the secret used to make the generated id harder to guess
-	Salt string
+	Salt string `json:"salt"`
 }
 
 // NewData creates a new HashIDData with the DefaultAlphabet already set.
@@ -75,6 +82,14 @@ func NewWithData(data *HashIDData) (*HashID, error) {
 		}
 		uniqueCheck[a] = true
 	}
+	if data.ExactLength != 0 {
+		if data.ExactLength < 2 {
+			return nil, fmt.Errorf("exact length must be at least 2")
+		}
+		if data.MinLength > data.ExactLength {
+			return nil, fmt.Errorf("minimum length %d is greater than exact length %d", data.MinLength, data.ExactLength)
+		}
+	}
 
 	alphabet := []rune(data.Alphabet)
 	salt := []rune(data.Salt)
@@ -125,23 +140,98 @@ func NewWithData(data *HashIDData) (*HashID, error) {
 	}
 
 	hid := &HashID{
-		alphabet:  alphabet,
-		minLength: data.MinLength,
-		salt:      salt,
-		seps:      seps,
-		guards:    guards,
+		data:     *data,
+		alphabet: alphabet,
+		salt:     salt,
+		seps:     seps,
+		guards:   guards,
 	}
 
-	// Calculate the maximum possible string length by hashing the maximum possible id
+	// Calculate the maximum possible string length by hashing the maximum possible id.
+	// No minimum length is set yet so that this is the natural length, including one
+	// rune for either the lottery or the separator.
 	encoded, err := hid.EncodeInt64([]int64{math.MaxInt64})
 	if err != nil {
 		return nil, fmt.Errorf("Unable to encode maximum int64 to find max encoded value length: %s", err)
 	}
 	hid.maxLengthPerNumber = len(encoded)
 
+	hid.minLength = data.MinLength
+	if data.ExactLength > hid.minLength {
+		hid.minLength = data.ExactLength
+	}
+	hid.exactLength = data.ExactLength
+	hid.maxExactValue = math.MaxInt64
+	if hid.exactLength > 0 {
+		hid.maxExactValue = hid.MaxValueForLength(hid.exactLength)
+	}
+
 	return hid, nil
 }
 
+// MaxExactValue returns the largest single integer which can be encoded when ExactLength is set.
+// Without ExactLength, it returns math.MaxInt64.
+func (h *HashID) MaxExactValue() int64 {
+	return h.maxExactValue
+}
+
+// MaxEncodedLength returns the maximum length in runes of a hash encoding count numbers.
+func (h *HashID) MaxEncodedLength(count int) int {
+	if count <= 0 {
+		return 0
+	}
+	if h.exactLength > 0 {
+		return h.exactLength
+	}
+	length := h.maxLengthPerNumber * count
+	if length < h.minLength {
+		length = h.minLength
+	}
+	return length
+}
+
+// MaxValueForLength returns the largest single integer whose hash is at most n runes long.
+// It returns -1 when no integer can be encoded in n runes.
+func (h *HashID) MaxValueForLength(n int) int64 {
+	if n < 2 || n < h.minLength {
+		return -1
+	}
+	if h.exactLength > 0 && n > h.exactLength {
+		n = h.exactLength
+	}
+	// One rune is taken by the lottery
+	return maxValueForDigits(n-1, len(h.alphabet))
+}
+
+// EncodedLength returns the length in runes of the hash EncodeInt64 would return for numbers,
+// without generating it.
+func (h *HashID) EncodedLength(numbers []int64) (int, error) {
+	if len(numbers) == 0 {
+		return 0, errors.New("encoding empty array of numbers makes no sense")
+	}
+	// One rune for the lottery and one separator between each number
+	length := len(numbers)
+	for _, n := range numbers {
+		if n < 0 {
+			return 0, errors.New("negative number not supported")
+		}
+		for {
+			length++
+			n /= int64(len(h.alphabet))
+			if n == 0 {
+				break
+			}
+		}
+	}
+	if h.exactLength > 0 && length > h.exactLength {
+		return 0, fmt.Errorf("encoded length %d exceeds exact length %d", length, h.exactLength)
+	}
+	if length < h.minLength {
+		length = h.minLength
+	}
+	return length, nil
+}
+
 // Encode hashes an array of int to a string containing at least MinLength characters taken from the Alphabet.
 // Use Decode using the same Alphabet and Salt to get back the array of int.
 func (h *HashID) Encode(numbers []int) (string, error) {
@@ -197,6 +287,10 @@ func (h *HashID) EncodeInt64(numbers []int64) (string, error) {
 		}
 	}
 
+	if h.exactLength > 0 && len(result) > h.exactLength {
+		return "", fmt.Errorf("encoded length %d exceeds exact length %d", len(result), h.exactLength)
+	}
+
 	if len(result) < h.minLength {
 		guardIndex := (numbersHash + int64(result[0])) % int64(len(h.guards))
 		result = append([]rune{h.guards[guardIndex]}, result...)
@@ -290,6 +384,8 @@ func (h *HashID) DecodeInt64(hash string) []int64 {
 // DecodeInt64 unhashes the string passed to an array of int64.
 // It is symmetric with EncodeInt64 if the Alphabet and Salt are the same ones which were used to hash.
 // MinLength has no effect on DecodeInt64.
+// When the decoded numbers do not encode back to the same hash, they are returned along with the error.
+// Use DecodePartial to find out which parts of a hash failed to decode.
 func (h *HashID) DecodeInt64WithError(hash string) ([]int64, error) {
 	hashes := splitRunes([]rune(hash), h.guards)
 	hashIndex := 0
@@ -303,6 +399,11 @@ func (h *HashID) DecodeInt64WithError(hash string) ([]int64, error) {
 	if len(hashBreakdown) > 0 {
 		lottery := hashBreakdown[0]
 		hashBreakdown = hashBreakdown[1:]
+		// Position of the first sub-hash for errors, after the lottery and the first guard if any
+		offset := 1
+		if hashIndex == 1 {
+			offset += len(hashes[0]) + 1
+		}
 		hashes = splitRunes(hashBreakdown, h.seps)
 		alphabet := duplicateRuneSlice(h.alphabet)
 		buffer := make([]rune, len(alphabet)+len(h.salt)+1)
@@ -312,11 +413,12 @@ func (h *HashID) DecodeInt64WithError(hash string) ([]int64, error) {
 			buffer = append(buffer, h.salt...)
 			buffer = append(buffer, alphabet...)
 			consistentShuffleInPlace(alphabet, buffer[:len(alphabet)])
-			number, err := unhash(subHash, alphabet)
+			number, err := unhash(subHash, alphabet, offset)
 			if err != nil {
 				return nil, err
 			}
 			result = append(result, number)
+			offset += len(subHash) + 1
 		}
 	}
 
@@ -389,9 +491,22 @@ func hash(input int64, alphabet []rune, result []rune) []rune {
 	return result
 }
 
-func unhash(input, alphabet []rune) (int64, error) {
+var errAlphabetMismatch = errors.New("alphabet used for hash was different")
+
+// ErrOverflow is returned when decoding a number which does not fit in an int64
+type ErrOverflow struct {
+	// Position is the rune index in the hash where the number overflows
+	Position int
+}
+
+func (e *ErrOverflow) Error() string {
+	return fmt.Sprintf("number overflows int64 at position %d", e.Position)
+}
+
+// unhash decodes input into a number, offset is the position of input in the hash for errors
+func unhash(input, alphabet []rune, offset int) (int64, error) {
 	result := int64(0)
-	for _, inputRune := range input {
+	for i, inputRune := range input {
 		alphabetPos := -1
 		for pos, alphabetRune := range alphabet {
 			if inputRune == alphabetRune {
@@ -400,7 +515,10 @@ func unhash(input, alphabet []rune) (int64, error) {
 			}
 		}
 		if alphabetPos == -1 {
-			return 0, errors.New("alphabet used for hash was different")
+			return 0, errAlphabetMismatch
+		}
+		if result > (math.MaxInt64-int64(alphabetPos))/int64(len(alphabet)) {
+			return 0, &ErrOverflow{Position: offset + i}
 		}
 
 		result = result*int64(len(alphabet)) + int64(alphabetPos)
@@ -408,6 +526,18 @@ func unhash(input, alphabet []rune) (int64, error) {
 	return result, nil
 }
 
+// maxValueForDigits returns the largest integer written with at most digits runes of an alphabet of size base
+func maxValueForDigits(digits, base int) int64 {
+	result := int64(1)
+	for i := 0; i < digits; i++ {
+		if result > math.MaxInt64/int64(base) {
+			return math.MaxInt64
+		}
+		result *= int64(base)
+	}
+	return result - 1
+}
+
 func consistentShuffle(alphabet, salt []rune) []rune {
 	if len(salt) == 0 {
 		return alphabet
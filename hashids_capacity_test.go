@@ -0,0 +1,86 @@
+package hashids
+
+import (
+	"math"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncodedLength(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	for _, minLength := range []int{0, 10, 30} {
+		hdata.MinLength = minLength
+		hid, _ := NewWithData(hdata)
+
+		for _, numbers := range [][]int64{
+			{0},
+			{45, 434, 1313, 99},
+			{math.MaxInt64},
+			{math.MaxInt64, 0, 1024, math.MaxInt64 / 2},
+		} {
+			hash, err := hid.EncodeInt64(numbers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			length, err := hid.EncodedLength(numbers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if length != utf8.RuneCountInString(hash) {
+				t.Errorf("Expected length `%d` for `%v` but got `%d`", utf8.RuneCountInString(hash), numbers, length)
+			}
+		}
+	}
+}
+
+func TestEncodedLengthWithError(t *testing.T) {
+	hid, _ := New()
+	_, err := hid.EncodedLength([]int64{-1})
+	expected := "negative number not supported"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+}
+
+func TestMaxEncodedLength(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	maxNumbers := []int64{math.MaxInt64, math.MaxInt64, math.MaxInt64}
+	for count := 1; count <= len(maxNumbers); count++ {
+		hash, _ := hid.EncodeInt64(maxNumbers[:count])
+		if hid.MaxEncodedLength(count) != len(hash) {
+			t.Errorf("Expected max length `%d` for %d numbers but got `%d`", len(hash), count, hid.MaxEncodedLength(count))
+		}
+	}
+
+	hdata.MinLength = 100
+	hid, _ = NewWithData(hdata)
+	if hid.MaxEncodedLength(1) != 100 {
+		t.Errorf("Expected max length `100` but got `%d`", hid.MaxEncodedLength(1))
+	}
+}
+
+func TestMaxValueForLength(t *testing.T) {
+	hid, _ := New()
+
+	if hid.MaxValueForLength(1) != -1 {
+		t.Errorf("Expected no value to fit in 1 rune but got `%d`", hid.MaxValueForLength(1))
+	}
+	for n := 2; n <= 14; n++ {
+		maxValue := hid.MaxValueForLength(n)
+		hash, _ := hid.EncodeInt64([]int64{maxValue})
+		if len(hash) > n {
+			t.Errorf("Expected `%d` to fit in %d runes but got `%s`", maxValue, n, hash)
+		}
+		if maxValue == math.MaxInt64 {
+			continue
+		}
+		hash, _ = hid.EncodeInt64([]int64{maxValue + 1})
+		if len(hash) <= n {
+			t.Errorf("Expected `%d` not to fit in %d runes but got `%s`", maxValue+1, n, hash)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package hashids
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func testExactLength(exactLength int, t *testing.T) {
+	numbers := []int{1, 2, 3}
+	hdata := NewData()
+	hdata.ExactLength = exactLength
+	h, err := NewWithData(hdata)
+	if err != nil {
+		t.Fatalf("Expected no error but got `%s`", err)
+	}
+	e, err := h.Encode(numbers)
+	if err != nil {
+		t.Fatalf("Expected no error but got `%s`", err)
+	}
+	decodedNumbers := h.Decode(e)
+
+	if len(e) != exactLength {
+		t.Errorf("Expected hash length to be `%d`, was `%d`", exactLength, len(e))
+	}
+	if !reflect.DeepEqual(decodedNumbers, numbers) {
+		t.Errorf("Decoded numbers `%v` did not match with original `%v`", decodedNumbers, numbers)
+	}
+}
+
+func TestExactLengthWhen6(t *testing.T) {
+	testExactLength(6, t)
+}
+
+func TestExactLengthWhen10(t *testing.T) {
+	testExactLength(10, t)
+}
+
+func TestExactLengthWhen100(t *testing.T) {
+	testExactLength(100, t)
+}
+
+func TestExactLengthTooLong(t *testing.T) {
+	hdata := NewData()
+	hdata.ExactLength = 4
+	h, _ := NewWithData(hdata)
+	_, err := h.Encode([]int{1, 2, 3})
+	expected := "encoded length 6 exceeds exact length 4"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+}
+
+func TestExactLengthMaxValue(t *testing.T) {
+	hdata := NewData()
+	hdata.ExactLength = 4
+	h, _ := NewWithData(hdata)
+
+	maxValue := h.MaxExactValue()
+	e, err := h.EncodeInt64([]int64{maxValue})
+	if err != nil {
+		t.Fatalf("Expected no error encoding `%d` but got `%s`", maxValue, err)
+	}
+	if len(e) != 4 {
+		t.Errorf("Expected hash length to be `4`, was `%d`", len(e))
+	}
+	if _, err := h.EncodeInt64([]int64{maxValue + 1}); err == nil {
+		t.Errorf("Expected an error encoding `%d`", maxValue+1)
+	}
+
+	hdata.ExactLength = 0
+	h, _ = NewWithData(hdata)
+	if h.MaxExactValue() != math.MaxInt64 {
+		t.Errorf("Expected `%d` without exact length but got `%d`", int64(math.MaxInt64), h.MaxExactValue())
+	}
+}
+
+func TestExactLengthInvalid(t *testing.T) {
+	hdata := NewData()
+	hdata.ExactLength = 1
+	_, err := NewWithData(hdata)
+	expected := "exact length must be at least 2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+
+	hdata.ExactLength = 8
+	hdata.MinLength = 10
+	_, err = NewWithData(hdata)
+	expected = "minimum length 10 is greater than exact length 8"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+}
@@ -0,0 +1,104 @@
+package hashids
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeWithOverflow(t *testing.T) {
+	hdata := NewData()
+	hdata.MinLength = 30
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	hash, _ := hid.EncodeInt64([]int64{1, math.MaxInt64})
+	b, _ := hid.Inspect(hash)
+	runes := []rune(hash)
+
+	// Prepend the rune for 1 to the number, which makes it larger than math.MaxInt64
+	last := b.SubHashes[1]
+	one := string([]rune(last.Alphabet)[1])
+	overflow := string(runes[:last.Position]) + one + string(runes[last.Position:])
+
+	dec, err := hid.DecodeInt64WithError(overflow)
+	var errOverflow *ErrOverflow
+	if !errors.As(err, &errOverflow) {
+		t.Fatalf("Expected overflow error but got `%v` and `%v`", err, dec)
+	}
+	if expected := last.Position + len(last.Hash); errOverflow.Position != expected {
+		t.Errorf("Expected overflow at position %d but got %d", expected, errOverflow.Position)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	hdata := NewData()
+	hdata.MinLength = 30
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	for _, numbers := range [][]int64{
+		{0},
+		{45, 434, 1313, 99},
+		{math.MaxInt64},
+		{math.MaxInt64, 0, 1024, math.MaxInt64 / 2},
+	} {
+		hash, _ := hid.EncodeInt64(numbers)
+		f.Add(hash)
+		f.Add(hash + hash)
+		f.Add(strings.Repeat(hash[len(hash)/2:], 4))
+	}
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, hash string) {
+		hid.DecodePartial(hash)
+		hid.Inspect(hash)
+
+		numbers, err := hid.DecodeInt64WithError(hash)
+		if err != nil {
+			return
+		}
+		for _, n := range numbers {
+			if n < 0 {
+				t.Fatalf("Decoded negative number from `%s`: %v", hash, numbers)
+			}
+		}
+		if len(numbers) == 0 {
+			return
+		}
+		reencoded, err := hid.EncodeInt64(numbers)
+		if err != nil || reencoded != hash {
+			t.Fatalf("Decoded `%s` to %v which encodes to `%s`: %v", hash, numbers, reencoded, err)
+		}
+	})
+}
+
+func FuzzEncodeDecode(f *testing.F) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	f.Add(int64(0), int64(1))
+	f.Add(int64(45), int64(434))
+	f.Add(int64(math.MaxInt64), int64(math.MaxInt64))
+
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		if a < 0 || b < 0 {
+			return
+		}
+		numbers := []int64{a, b}
+		hash, err := hid.EncodeInt64(numbers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := hid.DecodeInt64WithError(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dec, numbers) {
+			t.Fatalf("Decoded numbers `%v` did not match with original `%v`", dec, numbers)
+		}
+	})
+}
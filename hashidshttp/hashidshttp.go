@@ -0,0 +1,125 @@
+// Package hashidshttp decodes hashids found in HTTP requests before they reach handlers.
+package hashidshttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+type contextKey struct{}
+
+// Middleware decodes named path values and query parameters with Codec and stores the numbers in the
+// request context, see Numbers and Int64. Requests with a value which fails to decode are answered with
+// an error and never reach the next handler. Missing values are ignored.
+type Middleware struct {
+	// Codec is used to decode every value
+	Codec *hashids.HashID
+
+	// PathValues are the names of the path wildcards to decode, see http.Request.PathValue
+	PathValues []string
+
+	// QueryParams are the names of the query parameters to decode
+	QueryParams []string
+
+	// Status is the status code used when a value fails to decode, http.StatusNotFound if zero
+	Status int
+
+	// ProblemJSON writes errors as application/problem+json (RFC 9457) instead of plain text
+	ProblemJSON bool
+}
+
+// Handler returns a handler decoding values before calling next.
+// To decode path values, it must be called by an http.ServeMux, eg. mux.Handle("/users/{id}", m.Handler(h)).
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decoded := make(map[string][]int64, len(m.PathValues)+len(m.QueryParams))
+		for _, name := range m.PathValues {
+			if value := r.PathValue(name); value != "" {
+				if !m.decode(w, decoded, name, value) {
+					return
+				}
+			}
+		}
+		if len(m.QueryParams) > 0 {
+			query := r.URL.Query()
+			for _, name := range m.QueryParams {
+				if value := query.Get(name); value != "" {
+					if !m.decode(w, decoded, name, value) {
+						return
+					}
+				}
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(withNumbers(r.Context(), decoded)))
+	})
+}
+
+func (m *Middleware) decode(w http.ResponseWriter, decoded map[string][]int64, name, value string) bool {
+	numbers, err := m.Codec.DecodeInt64WithError(value)
+	if err != nil || len(numbers) == 0 {
+		m.writeError(w, fmt.Sprintf("%q is not a valid id", name))
+		return false
+	}
+	decoded[name] = numbers
+	return true
+}
+
+func (m *Middleware) writeError(w http.ResponseWriter, detail string) {
+	status := m.Status
+	if status == 0 {
+		status = http.StatusNotFound
+	}
+	WriteError(w, status, detail, m.ProblemJSON)
+}
+
+// WriteError writes an error response with status, either as plain text or as application/problem+json.
+// The decoding error itself is never written, as it could reveal details about the codec.
+func WriteError(w http.ResponseWriter, status int, detail string, problemJSON bool) {
+	if !problemJSON {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	body, _ := json.Marshal(struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail,omitempty"`
+	}{"about:blank", http.StatusText(status), status, detail})
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func withNumbers(ctx context.Context, decoded map[string][]int64) context.Context {
+	if previous, ok := ctx.Value(contextKey{}).(map[string][]int64); ok {
+		// Keep the values decoded by an outer middleware
+		for name, numbers := range previous {
+			if _, found := decoded[name]; !found {
+				decoded[name] = numbers
+			}
+		}
+	}
+	return context.WithValue(ctx, contextKey{}, decoded)
+}
+
+// Numbers returns the numbers decoded from the value called name by a Middleware
+func Numbers(ctx context.Context, name string) ([]int64, bool) {
+	decoded, _ := ctx.Value(contextKey{}).(map[string][]int64)
+	numbers, ok := decoded[name]
+	return numbers, ok
+}
+
+// Int64 returns the number decoded from the value called name by a Middleware.
+// It returns false if the value is missing or does not contain exactly one number.
+func Int64(ctx context.Context, name string) (int64, bool) {
+	numbers, ok := Numbers(ctx, name)
+	if !ok || len(numbers) != 1 {
+		return 0, false
+	}
+	return numbers[0], true
+}
@@ -0,0 +1,70 @@
+package hashidshttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func newServer(m *Middleware) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/users/{id}", m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := Int64(r.Context(), "id")
+		owner, _ := Numbers(r.Context(), "owner")
+		fmt.Fprintf(w, "%d %v", id, owner)
+	})))
+	return mux
+}
+
+func TestMiddleware(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	id, _ := hid.EncodeInt64([]int64{42})
+	owner, _ := hid.EncodeInt64([]int64{1, 2})
+
+	mux := newServer(&Middleware{Codec: hid, PathValues: []string{"id"}, QueryParams: []string{"owner"}})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/users/"+id+"?owner="+owner, nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "42 [1 2]" {
+		t.Errorf("Expected `42 [1 2]` but got %d `%s`", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareWithError(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+
+	mux := newServer(&Middleware{Codec: hid, PathValues: []string{"id"}})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/users/notanid", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d but got %d", http.StatusNotFound, rec.Code)
+	}
+
+	mux = newServer(&Middleware{Codec: hid, PathValues: []string{"id"}, Status: http.StatusBadRequest, ProblemJSON: true})
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/users/notanid", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d but got %d", http.StatusBadRequest, rec.Code)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "application/problem+json" {
+		t.Errorf("Expected problem+json but got `%s`", contentType)
+	}
+	var problem struct {
+		Status int
+		Detail string
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Detail != `"id" is not a valid id` {
+		t.Errorf("Unexpected problem `%s`", rec.Body)
+	}
+}
@@ -0,0 +1,300 @@
+package hashidshttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// arrayElement is the path element matching any element of an array
+const arrayElement = "[*]"
+
+// Transformer rewrites the values found at some paths in JSON documents, from integers to hashids with
+// Encode and back with Decode. The rest of the documents is copied as is, keeping the order of keys.
+type Transformer struct {
+	codec *hashids.HashID
+	paths [][]string
+
+	// ProblemJSON writes errors as application/problem+json (RFC 9457) instead of plain text in Handler
+	ProblemJSON bool
+}
+
+// NewTransformer creates a Transformer rewriting values at paths with codec.
+// A path starts with $ followed by object keys like .id and arrays like [*], eg. $.items[*].owner_id
+func NewTransformer(codec *hashids.HashID, paths ...string) (*Transformer, error) {
+	t := &Transformer{codec: codec}
+	for _, path := range paths {
+		elements, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+		t.paths = append(t.paths, elements)
+	}
+	return t, nil
+}
+
+func parsePath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	var elements []string
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, arrayElement):
+			elements = append(elements, arrayElement)
+			rest = rest[len(arrayElement):]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			elements = append(elements, rest[1:end+1])
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q is invalid at %q, expected .key or [*]", path, rest)
+		}
+	}
+	return elements, nil
+}
+
+// Encode copies the JSON document from src to dst, replacing the integers found at the paths with hashids
+func (t *Transformer) Encode(dst io.Writer, src io.Reader) error {
+	return t.transform(dst, src, func(value interface{}) (interface{}, error) {
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected an integer but got %v", value)
+		}
+		n, err := strconv.ParseInt(string(number), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return t.codec.EncodeInt64([]int64{n})
+	})
+}
+
+// Decode copies the JSON document from src to dst, replacing the hashids found at the paths with integers
+func (t *Transformer) Decode(dst io.Writer, src io.Reader) error {
+	return t.transform(dst, src, func(value interface{}) (interface{}, error) {
+		hash, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a hashid but got %v", value)
+		}
+		numbers, err := t.codec.DecodeInt64WithError(hash)
+		if err != nil {
+			return nil, err
+		}
+		if len(numbers) != 1 {
+			return nil, fmt.Errorf("expected a single number in %q but got %d", hash, len(numbers))
+		}
+		return json.Number(strconv.FormatInt(numbers[0], 10)), nil
+	})
+}
+
+func (t *Transformer) transform(dst io.Writer, src io.Reader, fn func(interface{}) (interface{}, error)) error {
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := t.transformValue(dec, &buf, nil, fn); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after top-level value")
+	}
+	_, err := dst.Write(buf.Bytes())
+	return err
+}
+
+func (t *Transformer) transformValue(dec *json.Decoder, buf *bytes.Buffer, path []string, fn func(interface{}) (interface{}, error)) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		buf.WriteByte('{')
+		for i := 0; dec.More(); i++ {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeToken(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := t.transformValue(dec, buf, append(path, key.(string)), fn); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	case json.Delim('['):
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := t.transformValue(dec, buf, append(path, arrayElement), fn); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	if token != nil && t.matches(path) {
+		token, err = fn(token)
+		if err != nil {
+			return &PathError{Path: "$" + formatPath(path), Err: err}
+		}
+	}
+	return writeToken(buf, token)
+}
+
+// PathError is returned when the value at Path could not be rewritten
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+func (t *Transformer) matches(path []string) bool {
+	for _, p := range t.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		match := true
+		for i := range p {
+			if p[i] != path[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func formatPath(path []string) string {
+	var sb strings.Builder
+	for _, element := range path {
+		if element != arrayElement {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(element)
+	}
+	return sb.String()
+}
+
+func writeToken(buf *bytes.Buffer, token interface{}) error {
+	if number, ok := token.(json.Number); ok {
+		buf.WriteString(string(number))
+		return nil
+	}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(token); err != nil {
+		return err
+	}
+	// Encode always ends with a newline
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// Handler returns a handler which decodes the hashids in JSON request bodies before calling next,
+// and encodes the integers in its JSON responses. Requests which fail to decode are answered with
+// http.StatusBadRequest. Responses are buffered to be rewritten.
+func (t *Transformer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 && isJSON(r.Header.Get("Content-Type")) {
+			var body bytes.Buffer
+			err := t.Decode(&body, r.Body)
+			r.Body.Close()
+			if err != nil {
+				detail := "invalid JSON body"
+				var pathErr *PathError
+				if errors.As(err, &pathErr) {
+					detail = fmt.Sprintf("%q is not a valid id", pathErr.Path)
+				}
+				WriteError(w, http.StatusBadRequest, detail, t.ProblemJSON)
+				return
+			}
+			r.Body = io.NopCloser(&body)
+			r.ContentLength = int64(body.Len())
+			r.Header.Del("Content-Length")
+		}
+
+		rw := &bufferedResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		body := rw.body.Bytes()
+		if len(body) > 0 && isJSON(w.Header().Get("Content-Type")) {
+			var rewritten bytes.Buffer
+			if err := t.Encode(&rewritten, &rw.body); err != nil {
+				WriteError(w, http.StatusInternalServerError, "", t.ProblemJSON)
+				return
+			}
+			body = rewritten.Bytes()
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		if rw.status != 0 {
+			w.WriteHeader(rw.status)
+		}
+		w.Write(body)
+	})
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// bufferedResponseWriter keeps the response in memory until the handler is done
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
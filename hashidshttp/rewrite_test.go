@@ -0,0 +1,105 @@
+package hashidshttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func TestTransformer(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	id, _ := hid.EncodeInt64([]int64{42})
+	owner1, _ := hid.EncodeInt64([]int64{1})
+	owner2, _ := hid.EncodeInt64([]int64{2})
+
+	tr, err := NewTransformer(hid, "$.id", "$.items[*].owner_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := `{"id":42,"name":"<b>","items":[{"owner_id":1,"id":7},{"owner_id":2,"n":1.5}],"owner_id":3}`
+	expected := fmt.Sprintf(`{"id":"%s","name":"<b>","items":[{"owner_id":"%s","id":7},{"owner_id":"%s","n":1.5}],"owner_id":3}`, id, owner1, owner2)
+
+	var encoded bytes.Buffer
+	if err := tr.Encode(&encoded, strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	if encoded.String() != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, encoded.String())
+	}
+
+	var decoded bytes.Buffer
+	if err := tr.Decode(&decoded, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != src {
+		t.Errorf("Expected `%s` but got `%s`", src, decoded.String())
+	}
+}
+
+func TestTransformerWithError(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	tr, _ := NewTransformer(hid, "$.id")
+
+	err := tr.Decode(io.Discard, strings.NewReader(`{"id":"notanid"}`))
+	pathErr, ok := err.(*PathError)
+	if !ok || pathErr.Path != "$.id" {
+		t.Errorf("Expected error for `$.id` but got `%v`", err)
+	}
+	if err := tr.Encode(io.Discard, strings.NewReader(`{"id":-1}`)); err == nil {
+		t.Error("Expected error encoding a negative number")
+	}
+
+	for _, path := range []string{"id", "$.", "$.a[0]"} {
+		if _, err := NewTransformer(hid, path); err == nil {
+			t.Errorf("Expected error for path `%s`", path)
+		}
+	}
+}
+
+func TestTransformerHandler(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	id, _ := hid.EncodeInt64([]int64{42})
+	tr, _ := NewTransformer(hid, "$.id")
+
+	handler := tr.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ ID int64 }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d}`, body.ID+1)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"id":"`+id+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rec, req)
+
+	next, _ := hid.EncodeInt64([]int64{43})
+	if expected := `{"id":"` + next + `"}`; rec.Code != http.StatusCreated || rec.Body.String() != expected {
+		t.Errorf("Expected %d `%s` but got %d `%s`", http.StatusCreated, expected, rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"id":42}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
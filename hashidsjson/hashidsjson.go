@@ -0,0 +1,665 @@
+// Package hashidsjson marshals structs to JSON with their integer ids replaced by hashids.
+//
+// Integer fields tagged with hashid, eg.
+//
+//	UserID int64 `json:"user_id" hashid:"users"`
+//
+// are encoded with the codec registered under that name, and decoded back by Unmarshal.
+// The tag also applies to the elements of slices, arrays, maps and pointers of integers, and the
+// structs inside maps are walked like the others.
+// The json tags are honored like encoding/json does: renaming, omitempty, omitzero, string and "-",
+// as well as its rules choosing between the fields promoted from embedded structs.
+// Values which cannot contain a hashid tag are marshaled by encoding/json itself.
+package hashidsjson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// Registry gives the codec registered under a name, it is implemented by *hashids.Registry
+type Registry interface {
+	Get(name string) (*hashids.HashID, bool)
+}
+
+// Codecs is a Registry backed by a map
+type Codecs map[string]*hashids.HashID
+
+// Get returns the codec registered under name
+func (c Codecs) Get(name string) (*hashids.HashID, bool) {
+	codec, ok := c[name]
+	return codec, ok
+}
+
+// Marshal returns the JSON encoding of v, with the fields tagged with hashid encoded by the codecs in registry
+func Marshal(v interface{}, registry Registry) ([]byte, error) {
+	var buf bytes.Buffer
+	e := &encoder{registry: registry, buf: &buf}
+	if err := e.encode(reflect.ValueOf(v), ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal parses the JSON data into v, with the fields tagged with hashid decoded by the codecs in registry
+func Unmarshal(data []byte, v interface{}, registry Registry) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("hashidsjson: Unmarshal(non-pointer %T)", v)
+	}
+	d := &decoder{registry: registry}
+	return d.decode(data, rv.Elem(), "")
+}
+
+// field is a struct field as seen by encoding/json
+type field struct {
+	name string
+	// index is the path to the field through the embedded structs, its length is the depth of the field
+	index []int
+	// tagged is set when the name comes from the json tag
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+	// quoted is set by the string option, for the fields of the types it applies to
+	quoted bool
+	codec  string
+}
+
+// structFields returns the fields of t encoded by encoding/json, in the order of their index.
+// The fields promoted from embedded structs follow the rules of encoding/json when names conflict:
+// the shallowest field wins, then the one named by its json tag, and the others are dropped.
+func structFields(t reflect.Type) []field {
+	all := collectFields(t, nil, map[reflect.Type]bool{t: true})
+	byName := make(map[string][]field, len(all))
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	fields := all[:0]
+	for _, f := range all {
+		if dominant, ok := dominantField(byName[f.name]); ok && sameIndex(dominant.index, f.index) {
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return lessIndex(fields[i].index, fields[j].index) })
+	return fields
+}
+
+// collectFields returns the fields of t and of its embedded structs, prefixed by index.
+// embedding contains the structs being collected, to stop at embedding cycles.
+func collectFields(t reflect.Type, index []int, embedding map[reflect.Type]bool) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		name, options, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// Embedded struct fields are promoted
+				if !embedding[ft] {
+					embedding[ft] = true
+					fields = append(fields, collectFields(ft, fieldIndex, embedding)...)
+					delete(embedding, ft)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+		options = "," + options + ","
+		quoted := false
+		if strings.Contains(options, ",string,") {
+			ft := sf.Type
+			if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Bool, reflect.Float32, reflect.Float64, reflect.String:
+				quoted = true
+			default:
+				quoted = isInteger(ft.Kind())
+			}
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     fieldIndex,
+			tagged:    tagged,
+			omitEmpty: strings.Contains(options, ",omitempty,"),
+			omitZero:  strings.Contains(options, ",omitzero,"),
+			quoted:    quoted,
+			codec:     sf.Tag.Get("hashid"),
+		})
+	}
+	return fields
+}
+
+// dominantField returns the field encoded among fields with the same name, if any
+func dominantField(fields []field) (field, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+	var shallowest, tagged []field
+	for _, f := range fields {
+		if len(f.index) == depth {
+			shallowest = append(shallowest, f)
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], true
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return field{}, false
+}
+
+func sameIndex(a, b []int) bool {
+	return len(a) == len(b) && !lessIndex(a, b) && !lessIndex(b, a)
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// value returns the field in v, allocating embedded struct pointers if alloc is set
+func (f field) value(v reflect.Value, alloc bool) (reflect.Value, bool) {
+	for i, index := range f.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(index)
+	}
+	return v, true
+}
+
+// isEmptyValue reports the values omitted by omitempty, structs are never empty like in encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return isInteger(v.Kind()) && v.IsZero()
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+// isZeroValue reports the values omitted by omitzero, using their IsZero method if they have one
+func isZeroValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(isZeroer); ok {
+		return z.IsZero()
+	}
+	if v.CanAddr() {
+		if z, ok := v.Addr().Interface().(isZeroer); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func lookup(registry Registry, name string) (*hashids.HashID, error) {
+	codec, ok := registry.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("hashidsjson: unknown codec %q", name)
+	}
+	return codec, nil
+}
+
+type encoder struct {
+	registry Registry
+	buf      *bytes.Buffer
+}
+
+// encode writes v, codec is the name of the codec for integers or empty
+func (e *encoder) encode(v reflect.Value, codec string) error {
+	if !v.IsValid() {
+		e.buf.WriteString("null")
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		if codec == "" && v.Type().Implements(marshalerType) {
+			return e.marshal(v)
+		}
+		return e.encode(v.Elem(), codec)
+	case reflect.Struct:
+		if v.Type().Implements(marshalerType) || reflect.PointerTo(v.Type()).Implements(marshalerType) ||
+			(codec == "" && !containsTag(v.Type())) {
+			return e.marshal(v)
+		}
+		e.buf.WriteByte('{')
+		first := true
+		for _, f := range structFields(v.Type()) {
+			fv, ok := f.value(v, false)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) || (f.omitZero && isZeroValue(fv)) {
+				continue
+			}
+			if !first {
+				e.buf.WriteByte(',')
+			}
+			first = false
+			name, _ := json.Marshal(f.name)
+			e.buf.Write(name)
+			e.buf.WriteByte(':')
+			if f.quoted && f.codec == "" {
+				if err := e.marshalQuoted(fv); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := e.encode(fv, f.codec); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte('}')
+		return nil
+	case reflect.Slice, reflect.Array:
+		if codec == "" && !containsTag(v.Type()) {
+			return e.marshal(v)
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.encode(v.Index(i), codec); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+	case reflect.Map:
+		if codec == "" && !containsTag(v.Type()) {
+			return e.marshal(v)
+		}
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		keys, err := mapKeys(v)
+		if err != nil {
+			return err
+		}
+		e.buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(key.name)
+			e.buf.Write(name)
+			e.buf.WriteByte(':')
+			if err := e.encode(v.MapIndex(key.value), codec); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte('}')
+		return nil
+	}
+
+	if codec == "" {
+		return e.marshal(v)
+	}
+	if !isInteger(v.Kind()) {
+		return fmt.Errorf("hashidsjson: hashid tag on non-integer type %s", v.Type())
+	}
+	h, err := lookup(e.registry, codec)
+	if err != nil {
+		return err
+	}
+	var n int64
+	if v.CanInt() {
+		n = v.Int()
+	} else {
+		n = int64(v.Uint())
+		if n < 0 {
+			return fmt.Errorf("hashidsjson: %d overflows int64", v.Uint())
+		}
+	}
+	hash, err := h.EncodeInt64([]int64{n})
+	if err != nil {
+		return err
+	}
+	b, _ := json.Marshal(hash)
+	e.buf.Write(b)
+	return nil
+}
+
+var (
+	marshalerType       = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// mapKey is a key of a map with its JSON name
+type mapKey struct {
+	value reflect.Value
+	name  string
+}
+
+// mapKeys returns the keys of the map v sorted by their JSON name like encoding/json
+func mapKeys(v reflect.Value) ([]mapKey, error) {
+	keys := make([]mapKey, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		key := mapKey{value: k}
+		switch {
+		case k.Kind() == reflect.String:
+			key.name = k.String()
+		case k.Type().Implements(textMarshalerType):
+			if k.Kind() == reflect.Ptr && k.IsNil() {
+				continue
+			}
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			key.name = string(text)
+		case k.CanInt():
+			key.name = strconv.FormatInt(k.Int(), 10)
+		case k.CanUint():
+			key.name = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, fmt.Errorf("hashidsjson: unsupported map key type %s", k.Type())
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+	return keys, nil
+}
+
+// parseMapKey returns the key of type t named name in JSON, trying encoding.TextUnmarshaler first like encoding/json
+func parseMapKey(name string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		key := reflect.New(t)
+		if err := key.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(name)); err != nil {
+			return reflect.Value{}, err
+		}
+		return key.Elem(), nil
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf(name).Convert(t), nil
+	case isInteger(t.Kind()):
+		key := reflect.New(t).Elem()
+		if key.CanInt() {
+			n, err := strconv.ParseInt(name, 10, 64)
+			if err != nil || key.OverflowInt(n) {
+				return reflect.Value{}, fmt.Errorf("hashidsjson: invalid map key %q for %s", name, t)
+			}
+			key.SetInt(n)
+		} else {
+			n, err := strconv.ParseUint(name, 10, 64)
+			if err != nil || key.OverflowUint(n) {
+				return reflect.Value{}, fmt.Errorf("hashidsjson: invalid map key %q for %s", name, t)
+			}
+			key.SetUint(n)
+		}
+		return key, nil
+	}
+	return reflect.Value{}, fmt.Errorf("hashidsjson: unsupported map key type %s", t)
+}
+
+func (e *encoder) marshal(v reflect.Value) error {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// marshalQuoted writes v as a JSON string containing its JSON encoding, for the string option
+func (e *encoder) marshalQuoted(v reflect.Value) error {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	if string(b) != "null" {
+		b, _ = json.Marshal(string(b))
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+type decoder struct {
+	registry Registry
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decode parses data into v, codec is the name of the codec for integers or empty
+func (d *decoder) decode(data []byte, v reflect.Value, codec string) error {
+	if codec == "" && !containsTag(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	if string(bytes.TrimSpace(data)) == "null" {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(data, v.Elem(), codec)
+	case reflect.Struct:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		fields := structFields(v.Type())
+		for key, value := range raw {
+			f, ok := findField(fields, key)
+			if !ok {
+				continue
+			}
+			fv, _ := f.value(v, true)
+			if f.quoted && f.codec == "" {
+				var err error
+				if value, err = unquoteValue(value, fv.Type()); err != nil {
+					return err
+				}
+				if value == nil {
+					continue
+				}
+			}
+			if err := d.decode(value, fv, f.codec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		var raw []json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(raw), len(raw)))
+		}
+		for i := 0; i < len(raw) && i < v.Len(); i++ {
+			if err := d.decode(raw[i], v.Index(i), codec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(raw)))
+		}
+		for name, value := range raw {
+			key, err := parseMapKey(name, v.Type().Key())
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(value, elem, codec); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+
+	if !isInteger(v.Kind()) {
+		return fmt.Errorf("hashidsjson: hashid tag on non-integer type %s", v.Type())
+	}
+	h, err := lookup(d.registry, codec)
+	if err != nil {
+		return err
+	}
+	var hash string
+	if err := json.Unmarshal(data, &hash); err != nil {
+		return fmt.Errorf("hashidsjson: expected a hashid for %s: %s", v.Type(), err)
+	}
+	numbers, err := h.DecodeInt64WithError(hash)
+	if err != nil {
+		return err
+	}
+	if len(numbers) != 1 {
+		return fmt.Errorf("hashidsjson: expected a single number in %q but got %d", hash, len(numbers))
+	}
+	if v.CanInt() {
+		if v.OverflowInt(numbers[0]) {
+			return fmt.Errorf("hashidsjson: %d overflows %s", numbers[0], v.Type())
+		}
+		v.SetInt(numbers[0])
+	} else {
+		if v.OverflowUint(uint64(numbers[0])) {
+			return fmt.Errorf("hashidsjson: %d overflows %s", numbers[0], v.Type())
+		}
+		v.SetUint(uint64(numbers[0]))
+	}
+	return nil
+}
+
+// unquoteValue returns the JSON encoding inside the JSON string data, for the string option.
+// It returns nil for null, which leaves the field unchanged like encoding/json.
+func unquoteValue(data []byte, t reflect.Type) ([]byte, error) {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("hashidsjson: invalid use of ,string struct tag, trying to unmarshal %s into %s", data, t)
+	}
+	return []byte(s), nil
+}
+
+// findField returns the field for a JSON key, preferring an exact match like encoding/json
+func findField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// containsTag returns whether values of type t may contain fields tagged with hashid
+func containsTag(t reflect.Type) bool {
+	return containsTagSeen(t, map[reflect.Type]bool{})
+}
+
+func containsTagSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsTagSeen(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range structFields(t) {
+			if f.codec != "" {
+				return true
+			}
+			ft := t.FieldByIndex(f.index).Type
+			if containsTagSeen(ft, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package hashidsjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+type Owner struct {
+	ID   int64  `json:"id" hashid:"users"`
+	Name string `json:"name"`
+}
+
+type Base struct {
+	Version int `json:"version"`
+}
+
+type Order struct {
+	Base
+	ID       int64    `json:"id" hashid:"orders"`
+	Owner    *Owner   `json:"owner,omitempty"`
+	Related  []uint32 `json:"related,omitempty" hashid:"orders"`
+	Parent   *int     `json:"parent" hashid:"orders"`
+	Items    []Owner  `json:"items"`
+	Note     string   `json:"-"`
+	Untagged int64
+}
+
+func newCodecs(t *testing.T) Codecs {
+	codecs := Codecs{}
+	for _, name := range []string{"users", "orders"} {
+		hdata := hashids.NewData()
+		hdata.Salt = name
+		hid, err := hashids.NewWithData(hdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codecs[name] = hid
+	}
+	return codecs
+}
+
+func encode(t *testing.T, codecs Codecs, name string, n int64) string {
+	hash, err := codecs[name].EncodeInt64([]int64{n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	codecs := newCodecs(t)
+	parent := 3
+	order := Order{
+		Base:     Base{Version: 2},
+		ID:       1,
+		Owner:    &Owner{ID: 42, Name: "alice"},
+		Parent:   &parent,
+		Items:    []Owner{{ID: 7}},
+		Note:     "ignored",
+		Untagged: 5,
+	}
+
+	data, err := Marshal(order, codecs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf(`{"version":2,"id":"%s","owner":{"id":"%s","name":"alice"},"parent":"%s","items":[{"id":"%s","name":""}],"Untagged":5}`,
+		encode(t, codecs, "orders", 1), encode(t, codecs, "users", 42), encode(t, codecs, "orders", 3), encode(t, codecs, "users", 7))
+	if string(data) != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, data)
+	}
+
+	var decoded Order
+	if err := Unmarshal(data, &decoded, codecs); err != nil {
+		t.Fatal(err)
+	}
+	order.Note = ""
+	if !reflect.DeepEqual(decoded, order) {
+		t.Errorf("Expected `%+v` but got `%+v`", order, decoded)
+	}
+}
+
+func TestMarshalSlice(t *testing.T) {
+	codecs := newCodecs(t)
+	order := Order{Related: []uint32{4, 5}}
+
+	data, err := Marshal(&order, codecs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Order
+	if err := Unmarshal(data, &decoded, codecs); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Related, order.Related) {
+		t.Errorf("Expected `%v` but got `%v` from `%s`", order.Related, decoded.Related, data)
+	}
+}
+
+func TestUnmarshalWithError(t *testing.T) {
+	codecs := newCodecs(t)
+	var order Order
+
+	if err := Unmarshal([]byte(`{"id":"not a hashid"}`), &order, codecs); err == nil {
+		t.Error("Expected error decoding an invalid hashid")
+	}
+	if err := Unmarshal([]byte(`{"id":1}`), &order, codecs); err == nil {
+		t.Error("Expected error decoding an integer instead of a hashid")
+	}
+	if _, err := Marshal(Owner{ID: 1}, Codecs{}); err == nil {
+		t.Error("Expected error with an unknown codec")
+	}
+}
+
+type Options struct {
+	ID      int64     `json:"id" hashid:"users"`
+	Count   int64     `json:"count,string"`
+	Ratio   *float64  `json:"ratio,string"`
+	Label   string    `json:"label,string"`
+	Empty   int       `json:"empty,omitempty"`
+	Nested  Base      `json:"nested,omitempty"`
+	Zero    Base      `json:"zero,omitzero"`
+	Created time.Time `json:"created,omitzero"`
+}
+
+// TestMarshalMatchesEncodingJSON checks that the fields without a hashid tag are marshaled like encoding/json does
+func TestMarshalMatchesEncodingJSON(t *testing.T) {
+	codecs := newCodecs(t)
+	ratio := 0.5
+	values := []Options{
+		{ID: 1, Count: 5, Ratio: &ratio, Label: `a "label"`},
+		{ID: 2, Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Nested: Base{Version: 3}},
+	}
+	for _, v := range values {
+		data, err := Marshal(v, codecs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want map[string]json.RawMessage
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %s", data, err)
+		}
+		json.Unmarshal(expected, &want)
+		if id := string(got["id"]); id != fmt.Sprintf("%q", encode(t, codecs, "users", v.ID)) {
+			t.Errorf("Expected a hashid for id but got %s", id)
+		}
+		delete(got, "id")
+		delete(want, "id")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected `%s` but got `%s`", expected, data)
+		}
+
+		var decoded Options
+		if err := Unmarshal(data, &decoded, codecs); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, v) {
+			t.Errorf("Expected `%+v` but got `%+v`", v, decoded)
+		}
+	}
+}
+
+func TestUnmarshalStringOption(t *testing.T) {
+	codecs := newCodecs(t)
+	var v Options
+	if err := Unmarshal([]byte(`{"count":"12","label":"\"x\"","ratio":null}`), &v, codecs); err != nil {
+		t.Fatal(err)
+	}
+	if v.Count != 12 || v.Label != "x" || v.Ratio != nil {
+		t.Errorf("Unexpected %+v", v)
+	}
+	if err := Unmarshal([]byte(`{"count":12}`), &v, codecs); err == nil {
+		t.Error("Expected error decoding a number for a field with the string option")
+	}
+}
+
+type Index struct {
+	Owners  map[string]Owner  `json:"owners"`
+	ByOrder map[int64]*Owner  `json:"by_order"`
+	Counts  map[string]uint32 `json:"counts" hashid:"orders"`
+}
+
+func TestMarshalMap(t *testing.T) {
+	codecs := newCodecs(t)
+	index := Index{
+		Owners:  map[string]Owner{"b": {ID: 5, Name: "bob"}, "a": {ID: 6}},
+		ByOrder: map[int64]*Owner{10: {ID: 7}, 2: nil},
+		Counts:  map[string]uint32{"x": 3},
+	}
+	data, err := Marshal(index, codecs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf(`{"owners":{"a":{"id":"%s","name":""},"b":{"id":"%s","name":"bob"}},"by_order":{"10":{"id":"%s","name":""},"2":null},"counts":{"x":"%s"}}`,
+		encode(t, codecs, "users", 6), encode(t, codecs, "users", 5), encode(t, codecs, "users", 7), encode(t, codecs, "orders", 3))
+	if string(data) != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, data)
+	}
+
+	var decoded Index
+	if err := Unmarshal(data, &decoded, codecs); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, index) {
+		t.Errorf("Expected `%+v` but got `%+v`", index, decoded)
+	}
+	if err := Unmarshal([]byte(`{"by_order":{"x":null}}`), &decoded, codecs); err == nil {
+		t.Error("Expected an error decoding an invalid integer key")
+	}
+}
+
+type Inner struct {
+	ID    int64 `json:"id" hashid:"users"`
+	Name  string
+	Label string
+}
+
+type Tagged struct {
+	Label string `json:"Label"`
+}
+
+type Untagged struct {
+	Name string
+}
+
+type Outer struct {
+	Inner
+	Tagged
+	Untagged
+	ID string `json:"id"`
+}
+
+// TestEmbeddedConflicts checks that the fields promoted from embedded structs are chosen like encoding/json does
+func TestEmbeddedConflicts(t *testing.T) {
+	codecs := newCodecs(t)
+	v := Outer{Inner: Inner{ID: 1, Name: "inner", Label: "inner"}, Tagged: Tagged{Label: "tagged"}, Untagged: Untagged{Name: "untagged"}, ID: "x"}
+	data, err := Marshal(v, codecs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := json.Marshal(v)
+	if string(data) != string(expected) {
+		t.Errorf("Expected `%s` but got `%s`", expected, data)
+	}
+
+	var decoded Outer
+	if err := Unmarshal([]byte(`{"id":"y","Name":"n","Label":"l"}`), &decoded, codecs); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != "y" || decoded.Inner.ID != 0 || decoded.Inner.Name != "" || decoded.Untagged.Name != "" || decoded.Tagged.Label != "l" {
+		t.Errorf("Unexpected `%+v`", decoded)
+	}
+}
+
+var _ Registry = hashids.NewRegistry()
@@ -0,0 +1,103 @@
+// Package hashidsslog keeps internal integer ids out of logs by logging their hashids instead.
+package hashidsslog
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+// Redacted replaces ids which can not be encoded, so that they are never logged as integers
+const Redacted = "[redacted]"
+
+// ID is an integer id which is logged as its hashid, eg. slog.Any("user", hashidsslog.ID{Value: id, Codec: users})
+type ID struct {
+	Value int64
+	Codec *hashids.HashID
+}
+
+// LogValue implements slog.LogValuer
+func (id ID) LogValue() slog.Value {
+	return encode(id.Codec, id.Value)
+}
+
+func encode(codec *hashids.HashID, n int64) slog.Value {
+	hash, err := codec.EncodeInt64([]int64{n})
+	if err != nil {
+		return slog.StringValue(Redacted)
+	}
+	return slog.StringValue(hash)
+}
+
+// Handler replaces the integer attributes with the configured keys by their hashids, in groups too,
+// before passing records to the next handler.
+type Handler struct {
+	next  slog.Handler
+	codec *hashids.HashID
+	keys  map[string]bool
+}
+
+// NewHandler creates a Handler encoding the attributes named after keys with codec
+func NewHandler(next slog.Handler, codec *hashids.HashID, keys ...string) *Handler {
+	h := &Handler{next: next, codec: codec, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		h.keys[key] = true
+	}
+	return h
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	rewritten := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		rewritten.AddAttrs(h.rewrite(a))
+		return true
+	})
+	return h.next.Handle(ctx, rewritten)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rewritten := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		rewritten[i] = h.rewrite(a)
+	}
+	return &Handler{next: h.next.WithAttrs(rewritten), codec: h.codec, keys: h.keys}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), codec: h.codec, keys: h.keys}
+}
+
+func (h *Handler) rewrite(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		group := value.Group()
+		rewritten := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			rewritten[i] = h.rewrite(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(rewritten...)}
+	}
+	if !h.keys[a.Key] {
+		return a
+	}
+	switch value.Kind() {
+	case slog.KindInt64:
+		return slog.Attr{Key: a.Key, Value: encode(h.codec, value.Int64())}
+	case slog.KindUint64:
+		if value.Uint64() > math.MaxInt64 {
+			return slog.String(a.Key, Redacted)
+		}
+		return slog.Attr{Key: a.Key, Value: encode(h.codec, int64(value.Uint64()))}
+	}
+	return a
+}
@@ -0,0 +1,68 @@
+package hashidsslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/speps/go-hashids/v2"
+)
+
+func mustEncode(t *testing.T, hid *hashids.HashID, n int64) string {
+	hash, err := hid.EncodeInt64([]int64{n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestID(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+
+	logger.Info("login", "user", ID{Value: 42, Codec: hid}, "bad", ID{Value: -1, Codec: hid})
+
+	expected := "level=INFO msg=login user=" + mustEncode(t, hid, 42) + " bad=" + Redacted + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, buf.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	hdata := hashids.NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := hashids.NewWithData(hdata)
+	var buf bytes.Buffer
+	next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime})
+	logger := slog.New(NewHandler(next, hid, "user_id", "order_id"))
+
+	logger.With("user_id", 42).WithGroup("order").Info("paid",
+		"order_id", uint(7), "amount", 10, slog.Group("item", "order_id", 8, "user_id", "alice"))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	order := entry["order"].(map[string]interface{})
+	item := order["item"].(map[string]interface{})
+	if entry["user_id"] != mustEncode(t, hid, 42) {
+		t.Errorf("Expected user_id `%s` but got `%v`", mustEncode(t, hid, 42), entry["user_id"])
+	}
+	if order["order_id"] != mustEncode(t, hid, 7) || item["order_id"] != mustEncode(t, hid, 8) {
+		t.Errorf("Expected order ids to be encoded but got `%s`", buf.String())
+	}
+	if order["amount"] != float64(10) || item["user_id"] != "alice" {
+		t.Errorf("Expected other attributes to be kept but got `%s`", buf.String())
+	}
+}
+
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey && len(groups) == 0 {
+		return slog.Attr{}
+	}
+	return a
+}
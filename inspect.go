@@ -0,0 +1,146 @@
+package hashids
+
+import "fmt"
+
+// Breakdown describes each step taken to decode a hash, see Inspect.
+// Positions are rune indices in the inspected hash.
+type Breakdown struct {
+	// Hash is the inspected hash
+	Hash string
+
+	// Guards contains the guard runes found in the hash
+	Guards []RunePosition
+
+	// Lottery is the rune used to shuffle the alphabet, at LotteryPosition.
+	// LotteryPosition is -1 when the hash has no lottery rune.
+	Lottery         rune
+	LotteryPosition int
+
+	// Separators contains the runes found between the numbers
+	Separators []RunePosition
+
+	// SubHashes contains the part of the hash for each number
+	SubHashes []SubHash
+
+	// Numbers contains the numbers decoded from SubHashes
+	Numbers []int64
+
+	// Reencoded is the result of encoding Numbers again, it must match Hash
+	Reencoded string
+
+	// Reason explains why the hash did not decode, it is empty for a valid hash
+	Reason string
+}
+
+// RunePosition is a rune found at Position in a hash
+type RunePosition struct {
+	Rune     rune
+	Position int
+}
+
+// SubHash is the part of a hash encoding a single number
+type SubHash struct {
+	// Hash is the part of the hash, starting at Position
+	Hash     string
+	Position int
+
+	// Alphabet is the shuffled alphabet used to decode Hash into Number
+	Alphabet string
+	Number   int64
+}
+
+// Inspect decodes hash step by step like DecodeInt64WithError and reports what was found at each step.
+// The Breakdown is returned even when hash does not decode, along with the same error DecodeInt64WithError returns.
+func (h *HashID) Inspect(hash string) (*Breakdown, error) {
+	runes := []rune(hash)
+	b := &Breakdown{Hash: hash, LotteryPosition: -1}
+
+	for i, r := range runes {
+		if containsRune(h.guards, r) {
+			b.Guards = append(b.Guards, RunePosition{Rune: r, Position: i})
+		}
+	}
+
+	start, end := h.hashBounds(runes)
+	if start < end {
+		b.Lottery = runes[start]
+		b.LotteryPosition = start
+		alphabet := duplicateRuneSlice(h.alphabet)
+		buffer := make([]rune, len(alphabet)+len(h.salt)+1)
+
+		subStart := start + 1
+		for i := subStart; i <= end; i++ {
+			if i < end && !containsRune(h.seps, runes[i]) {
+				continue
+			}
+			if i < end {
+				b.Separators = append(b.Separators, RunePosition{Rune: runes[i], Position: i})
+			}
+
+			buffer = buffer[:1]
+			buffer[0] = b.Lottery
+			buffer = append(buffer, h.salt...)
+			buffer = append(buffer, alphabet...)
+			consistentShuffleInPlace(alphabet, buffer[:len(alphabet)])
+
+			subHash := runes[subStart:i]
+			number, err := unhash(subHash, alphabet, subStart)
+			b.SubHashes = append(b.SubHashes, SubHash{
+				Hash:     string(subHash),
+				Position: subStart,
+				Alphabet: string(alphabet),
+				Number:   number,
+			})
+			if err != nil {
+				b.Reason = err.Error()
+				return b, err
+			}
+			b.Numbers = append(b.Numbers, number)
+			subStart = i + 1
+		}
+	}
+
+	b.Reencoded, _ = h.EncodeInt64(b.Numbers)
+	if b.Reencoded != hash {
+		err := fmt.Errorf("mismatch between encode and decode: %s start %s"+
+			" re-encoded. result: %v", hash, b.Reencoded, b.Numbers)
+		b.Reason = err.Error()
+		return b, err
+	}
+
+	return b, nil
+}
+
+// hashBounds returns where the lottery and numbers are in a hash, in between guards if any.
+// It splits the hash the same way DecodeInt64WithError does.
+func (h *HashID) hashBounds(runes []rune) (start, end int) {
+	var guardIndices [2]int
+	guardCount := 0
+	for i, r := range runes {
+		if containsRune(h.guards, r) {
+			if guardCount < len(guardIndices) {
+				guardIndices[guardCount] = i
+			}
+			guardCount++
+		}
+	}
+	switch guardCount {
+	case 0:
+		return 0, len(runes)
+	case 1:
+		return guardIndices[0] + 1, len(runes)
+	case 2:
+		return guardIndices[0] + 1, guardIndices[1]
+	default:
+		return 0, guardIndices[0]
+	}
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, x := range runes {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package hashids
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	hdata := NewData()
+	hdata.MinLength = 30
+	hdata.Salt = "this is my salt"
+
+	hid, _ := NewWithData(hdata)
+
+	numbers := []int64{45, 434, 1313, 99}
+	hash, _ := hid.EncodeInt64(numbers)
+	b, err := hid.Inspect(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(b.Numbers, numbers) {
+		t.Errorf("Decoded numbers `%v` did not match with original `%v`", b.Numbers, numbers)
+	}
+	if len(b.Guards) != 2 {
+		t.Errorf("Expected 2 guards but got `%v`", b.Guards)
+	}
+	if len(b.Separators) != len(numbers)-1 {
+		t.Errorf("Expected %d separators but got `%v`", len(numbers)-1, b.Separators)
+	}
+	if b.Reencoded != hash || b.Reason != "" {
+		t.Errorf("Expected `%s` to verify but got `%s`: %s", hash, b.Reencoded, b.Reason)
+	}
+
+	runes := []rune(hash)
+	if runes[b.LotteryPosition] != b.Lottery {
+		t.Errorf("Expected lottery `%c` at %d in `%s`", b.Lottery, b.LotteryPosition, hash)
+	}
+	for _, s := range b.Separators {
+		if runes[s.Position] != s.Rune {
+			t.Errorf("Expected separator `%c` at %d in `%s`", s.Rune, s.Position, hash)
+		}
+	}
+	for _, s := range b.SubHashes {
+		if sub := string(runes[s.Position : s.Position+len([]rune(s.Hash))]); sub != s.Hash {
+			t.Errorf("Expected sub-hash `%s` at %d in `%s` but got `%s`", s.Hash, s.Position, hash, sub)
+		}
+		if len([]rune(s.Alphabet)) != len(hid.alphabet) {
+			t.Errorf("Expected alphabet of length %d but got `%s`", len(hid.alphabet), s.Alphabet)
+		}
+	}
+}
+
+func TestInspectWithError(t *testing.T) {
+	hdata := NewData()
+	hdata.Alphabet = "PleasAkMEFoThStx"
+	hdata.Salt = "temp"
+
+	hidEncode, _ := NewWithData(hdata)
+	hash, _ := hidEncode.Encode([]int{45, 434, 1313, 99})
+
+	hdata.Salt = "test"
+	hidDecode, _ := NewWithData(hdata)
+
+	for _, h := range []string{hash, "MAkhkloFAxAoskaZ"} {
+		_, decodeErr := hidDecode.DecodeInt64WithError(h)
+		b, err := hidDecode.Inspect(h)
+		if err == nil || decodeErr == nil || err.Error() != decodeErr.Error() {
+			t.Errorf("Expected error `%s` but got `%s`", decodeErr, err)
+		}
+		if b == nil || b.Reason != err.Error() {
+			t.Errorf("Expected breakdown with reason `%s` but got `%v`", err, b)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package hashids
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FailureKind tells why part of a hash failed to decode
+type FailureKind int
+
+const (
+	// FailureUnknownRune means a rune of the hash is not in the alphabet
+	FailureUnknownRune FailureKind = iota + 1
+	// FailureOverflow means a number does not fit in an int64
+	FailureOverflow
+	// FailureSaltMismatch means the decoded numbers do not encode back to the hash,
+	// usually because it was generated with a different salt or alphabet
+	FailureSaltMismatch
+)
+
+func (k FailureKind) String() string {
+	switch k {
+	case FailureUnknownRune:
+		return "unknown rune"
+	case FailureOverflow:
+		return "overflow"
+	case FailureSaltMismatch:
+		return "salt mismatch"
+	}
+	return fmt.Sprintf("FailureKind(%d)", int(k))
+}
+
+// PartialFailure describes a part of a hash which failed to decode
+type PartialFailure struct {
+	// Index is the index of the number which failed, or -1 when the failure concerns the whole hash
+	Index int
+
+	// Position is the rune index in the hash where the failing part starts
+	Position int
+
+	Kind FailureKind
+	Err  error
+}
+
+// PartialResult is the result of DecodePartial
+type PartialResult struct {
+	// Numbers contains one entry for each number found in the hash, failed ones are left to 0
+	Numbers []int64
+
+	// Failures contains every part of the hash which failed to decode, it is empty for a valid hash
+	Failures []PartialFailure
+}
+
+// Failed returns whether the number at index i failed to decode
+func (r *PartialResult) Failed(i int) bool {
+	for _, f := range r.Failures {
+		if f.Index == i {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodePartial unhashes as many numbers as possible from hash instead of stopping at the first error.
+// The numbers which decoded cleanly are kept in the result and every failure is reported with its kind.
+// A salt mismatch is only reported when every number decoded cleanly.
+func (h *HashID) DecodePartial(hash string) *PartialResult {
+	runes := []rune(hash)
+	result := &PartialResult{}
+
+	start, end := h.hashBounds(runes)
+	if start < end {
+		lottery := runes[start]
+		alphabet := duplicateRuneSlice(h.alphabet)
+		buffer := make([]rune, len(alphabet)+len(h.salt)+1)
+
+		subStart := start + 1
+		for i := subStart; i <= end; i++ {
+			if i < end && !containsRune(h.seps, runes[i]) {
+				continue
+			}
+
+			buffer = buffer[:1]
+			buffer[0] = lottery
+			buffer = append(buffer, h.salt...)
+			buffer = append(buffer, alphabet...)
+			consistentShuffleInPlace(alphabet, buffer[:len(alphabet)])
+
+			number, err := unhash(runes[subStart:i], alphabet, subStart)
+			if err != nil {
+				kind := FailureUnknownRune
+				var overflow *ErrOverflow
+				if errors.As(err, &overflow) {
+					kind = FailureOverflow
+				}
+				result.Failures = append(result.Failures, PartialFailure{
+					Index:    len(result.Numbers),
+					Position: subStart,
+					Kind:     kind,
+					Err:      err,
+				})
+			}
+			result.Numbers = append(result.Numbers, number)
+			subStart = i + 1
+		}
+	}
+
+	if len(result.Failures) == 0 {
+		sanityCheck, _ := h.EncodeInt64(result.Numbers)
+		if sanityCheck != hash {
+			result.Failures = append(result.Failures, PartialFailure{
+				Index:    -1,
+				Position: 0,
+				Kind:     FailureSaltMismatch,
+				Err: fmt.Errorf("mismatch between encode and decode: %s start %s"+
+					" re-encoded. result: %v", hash, sanityCheck, result.Numbers),
+			})
+		}
+	}
+
+	return result
+}
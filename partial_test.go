@@ -0,0 +1,82 @@
+package hashids
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodePartial(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	numbers := []int64{45, 434, 1313, 99}
+	hash, _ := hid.EncodeInt64(numbers)
+	result := hid.DecodePartial(hash)
+
+	if len(result.Failures) != 0 {
+		t.Errorf("Expected no failures but got `%v`", result.Failures)
+	}
+	if !reflect.DeepEqual(result.Numbers, numbers) {
+		t.Errorf("Decoded numbers `%v` did not match with original `%v`", result.Numbers, numbers)
+	}
+}
+
+func TestDecodePartialWithFailures(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	numbers := []int64{45, 434, 1313, 99}
+	hash, _ := hid.EncodeInt64(numbers)
+	b, _ := hid.Inspect(hash)
+	runes := []rune(hash)
+
+	// Replace the second number by an unknown rune and the third one by a number too large for an int64
+	second, third := b.SubHashes[1], b.SubHashes[2]
+	last := []rune(third.Alphabet)[len(third.Alphabet)-1]
+	broken := string(runes[:second.Position]) + "!" +
+		string(runes[second.Position+len(second.Hash):third.Position]) + strings.Repeat(string(last), 20) +
+		string(runes[third.Position+len(third.Hash):])
+
+	result := hid.DecodePartial(broken)
+	thirdPosition := third.Position - len(second.Hash) + 1
+
+	if len(result.Failures) != 2 {
+		t.Fatalf("Expected 2 failures but got `%v`", result.Failures)
+	}
+	if f := result.Failures[0]; f.Index != 1 || f.Kind != FailureUnknownRune || f.Position != second.Position {
+		t.Errorf("Expected unknown rune at index 1 but got `%v`", f)
+	}
+	if f := result.Failures[1]; f.Index != 2 || f.Kind != FailureOverflow || f.Position != thirdPosition {
+		t.Errorf("Expected overflow at index 2 but got `%v`", f)
+	}
+	if result.Numbers[0] != numbers[0] || result.Numbers[3] != numbers[3] {
+		t.Errorf("Expected numbers `%d` and `%d` to decode but got `%v`", numbers[0], numbers[3], result.Numbers)
+	}
+	if result.Failed(0) || !result.Failed(1) || !result.Failed(2) || result.Failed(3) {
+		t.Errorf("Expected only numbers 1 and 2 to fail but got `%v`", result.Failures)
+	}
+}
+
+func TestDecodePartialWithWrongSalt(t *testing.T) {
+	hdata := NewData()
+	hdata.Alphabet = "PleasAkMEFoThStx"
+	hdata.Salt = "temp"
+
+	hidEncode, _ := NewWithData(hdata)
+	hash, _ := hidEncode.Encode([]int{45, 434, 1313, 99})
+
+	hdata.Salt = "test"
+	hidDecode, _ := NewWithData(hdata)
+	result := hidDecode.DecodePartial(hash)
+
+	expected := []int64{7, 199, 245, 19}
+	if !reflect.DeepEqual(result.Numbers, expected) {
+		t.Errorf("Decoded numbers `%v` did not match with expected `%v`", result.Numbers, expected)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Kind != FailureSaltMismatch || result.Failures[0].Index != -1 {
+		t.Errorf("Expected a salt mismatch but got `%v`", result.Failures)
+	}
+}
@@ -0,0 +1,79 @@
+package hashids
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry maps names to codecs, eg. one for users and one for orders, each with its own salt.
+// It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]*HashID
+	policy WirePolicy
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{codecs: make(map[string]*HashID)}
+}
+
+// NewRegistryWithData creates a Registry with a codec for each named HashIDData.
+// Every codec is validated, the returned error lists all the invalid ones.
+func NewRegistryWithData(data map[string]*HashIDData) (*Registry, error) {
+	r := NewRegistry()
+	var errs []error
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hid, err := NewWithData(data[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("codec %q: %w", name, err))
+			continue
+		}
+		r.codecs[name] = hid
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, nil
+}
+
+// Register adds or replaces the codec called name
+func (r *Registry) Register(name string, h *HashID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[name] = h
+}
+
+// Unregister removes the codec called name, if any
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.codecs, name)
+}
+
+// Get returns the codec called name
+func (r *Registry) Get(name string) (*HashID, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.codecs[name]
+	return h, ok
+}
+
+// Names returns the sorted names of the codecs in the Registry
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.codecs))
+	for name := range r.codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
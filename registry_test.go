@@ -0,0 +1,212 @@
+package hashids
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	hid, _ := New()
+	r.Register("users", hid)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if h, ok := r.Get("users"); !ok || h != hid {
+				t.Error("Expected codec `users` to be registered")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := r.Get("orders"); ok {
+		t.Error("Expected codec `orders` not to be registered")
+	}
+	if names := r.Names(); len(names) != 1 || names[0] != "users" {
+		t.Errorf("Expected names `[users]` but got `%v`", names)
+	}
+
+	r.Unregister("users")
+	if _, ok := r.Get("users"); ok {
+		t.Error("Expected codec `users` to be unregistered")
+	}
+}
+
+func checkConfig(t *testing.T, config, format string) {
+	r, err := LoadRegistry(strings.NewReader(config), format)
+	if err != nil {
+		t.Fatalf("Expected no error but got `%s`", err)
+	}
+
+	hdata := NewData()
+	hdata.Salt = "users salt"
+	hdata.MinLength = 8
+	expected, _ := NewWithData(hdata)
+	users, _ := r.Get("users")
+	checkSameCodec(t, users, expected)
+
+	hdata = NewData()
+	hdata.Alphabet = "abcdefghijklmnopqrstuvwxyz"
+	hdata.Salt = "orders # salt"
+	expected, _ = NewWithData(hdata)
+	orders, _ := r.Get("orders")
+	checkSameCodec(t, orders, expected)
+}
+
+func checkSameCodec(t *testing.T, h, expected *HashID) {
+	if h == nil {
+		t.Fatal("Expected codec to be registered")
+	}
+	numbers := []int64{45, 434, 1313, 99}
+	hash, _ := h.EncodeInt64(numbers)
+	expectedHash, _ := expected.EncodeInt64(numbers)
+	if hash != expectedHash {
+		t.Errorf("Expected hash `%s` but got `%s`", expectedHash, hash)
+	}
+}
+
+func TestLoadRegistryJSON(t *testing.T) {
+	checkConfig(t, `{
+		"users": {"Salt": "users salt", "min_length": 8},
+		"orders": {"salt": "orders # salt", "alphabet": "abcdefghijklmnopqrstuvwxyz"}
+	}`, FormatJSON)
+}
+
+func TestLoadRegistryYAML(t *testing.T) {
+	checkConfig(t, `# codecs
+users:
+  salt: users salt
+  min_length: 8 # padded
+orders:
+  salt: "orders # salt"
+  alphabet: 'abcdefghijklmnopqrstuvwxyz'
+`, FormatYAML)
+}
+
+func TestLoadRegistryTOML(t *testing.T) {
+	checkConfig(t, `# codecs
+[users]
+salt = "users salt"
+MinLength = 8
+
+[orders]
+salt = "orders # salt" # comment
+alphabet = 'abcdefghijklmnopqrstuvwxyz'
+`, FormatTOML)
+}
+
+func TestLoadRegistryEnv(t *testing.T) {
+	r, err := LoadRegistryEnv("HASHID_", []string{
+		"HOME=/root",
+		"HASHID_USERS_SALT=users salt",
+		"HASHID_USERS_MIN_LENGTH=8",
+		"HASHID_ORDERS_SALT=orders # salt",
+		"HASHID_ORDERS_ALPHABET=abcdefghijklmnopqrstuvwxyz",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got `%s`", err)
+	}
+	hdata := NewData()
+	hdata.Salt = "users salt"
+	hdata.MinLength = 8
+	expected, _ := NewWithData(hdata)
+	users, _ := r.Get("users")
+	checkSameCodec(t, users, expected)
+}
+
+func TestLoadRegistryWithErrors(t *testing.T) {
+	_, err := LoadRegistry(strings.NewReader(`
+[users]
+min_length = "eight"
+pepper = "x"
+
+[orders]
+alphabet = "abc"
+
+[invoices]
+exact_length = 1
+`), FormatTOML)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	expected := `codec "users": min_length: strconv.Atoi: parsing "eight": invalid syntax
+pepper: unknown key`
+	if err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+
+	_, err = LoadRegistry(strings.NewReader(`
+[orders]
+alphabet = "abc"
+
+[invoices]
+exact_length = 1
+`), FormatTOML)
+	expected = `codec "invoices": exact length must be at least 2
+codec "orders": alphabet must contain at least 16 characters`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%s`", expected, err)
+	}
+}
+
+func TestLoadRegistryJSONWithInvalidValues(t *testing.T) {
+	_, err := LoadRegistry(strings.NewReader(`{
+		"users": {"salt": null, "min_length": [8]},
+		"orders": {"salt": {"value": "s"}, "alphabet": true}
+	}`), FormatJSON)
+	expected := `codec "orders": alphabet: expected a string or a number but got a boolean
+codec "orders": salt: expected a string or a number but got an object
+codec "users": min_length: expected a string or a number but got an array
+codec "users": salt: expected a string or a number but got null`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%v`", expected, err)
+	}
+}
+
+func TestLoadRegistryEnvWithUnknownVariables(t *testing.T) {
+	_, err := LoadRegistryEnv("HASHID_", []string{
+		"HASHID_USERS_SALT=users salt",
+		"HASHID_USERS_MINLEN=8",
+		"HASHID_ORDERS_MIN_LENGTH=eight",
+	})
+	expected := `HASHID_USERS_MINLEN: unknown variable, expected HASHID_<NAME> followed by one of _ALPHABET, _MIN_LENGTH, _EXACT_LENGTH, _SALT
+codec "orders": MIN_LENGTH: strconv.Atoi: parsing "eight": invalid syntax`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error `%s` but got `%v`", expected, err)
+	}
+}
+
+func TestLoadRegistryWithAmbiguousValues(t *testing.T) {
+	tests := []struct {
+		config, format, expected string
+	}{
+		{"[users]\nsalt = \"a\"\n\n[users]\nmin_length = 8\n", FormatTOML, `line 4: duplicate codec "users"`},
+		{"users:\n  salt: a\nusers:\n  min_length: 8\n", FormatYAML, `line 3: duplicate codec "users"`},
+		{"[users]\nsalt = \"a\"\nsalt = \"b\"\n", FormatTOML, `line 3: duplicate key "salt"`},
+		{"users:\n  salt: a\n  salt: b\n", FormatYAML, `line 3: duplicate key "salt"`},
+		{"[users]\nsalt = \"a\"\nSalt = \"b\"\n", FormatTOML, `codec "users": salt: same setting as Salt`},
+		{"[users]\nsalt = \"\"\"multi\"\"\"\n", FormatTOML, "line 2: multi-line strings are not supported"},
+		{"[users]\nsalt = '''multi'''\n", FormatTOML, "line 2: multi-line strings are not supported"},
+		{"[users]\nsalt = \"a\" trailing\n", FormatTOML, `line 2: unexpected "trailing" after the closing quote`},
+		{"users:\n  salt: 'a' trailing\n", FormatYAML, `line 2: unexpected "trailing" after the closing quote`},
+		{"users:\n  salt: |\n    a\n", FormatYAML, "line 2: block scalars are not supported"},
+	}
+	for _, test := range tests {
+		_, err := LoadRegistry(strings.NewReader(test.config), test.format)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("Expected error `%s` for %q but got `%v`", test.expected, test.config, err)
+		}
+	}
+
+	r, err := LoadRegistry(strings.NewReader("[users]\nsalt = \"a\" # comment\nalphabet = 'abcdefghijklmnopqrstuvwxyz' #\n"), FormatTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users, _ := r.Get("users"); users.Config().Salt != "a" {
+		t.Errorf("Expected salt `a` but got `%s`", users.Config().Salt)
+	}
+}
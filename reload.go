@@ -0,0 +1,183 @@
+package hashids
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reloadable is a codec rebuilt whenever its config file changes, which allows changing the salt or
+// the minimum length without restarting. The file contains a single HashIDData as JSON.
+//
+// The file is polled for changes, a new codec is only used if NewWithData accepts the new config.
+// After a change, hashes generated by the previous codec still decode during a grace period.
+type Reloadable struct {
+	path        string
+	gracePeriod time.Duration
+
+	current atomic.Pointer[reloadState]
+
+	mu        sync.Mutex
+	modTime   time.Time
+	onChange  []func(previous, current *HashID)
+	onError   func(error)
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type reloadState struct {
+	hid *HashID
+
+	// previous is used to decode until expires
+	previous *HashID
+	expires  time.Time
+}
+
+// NewReloadable loads the codec in path and polls it for changes every interval.
+// With an interval of 0 the file is not polled, and only reloaded by Reload.
+// Hashes from the previous codec still decode for gracePeriod after a change.
+// Close must be called to stop polling.
+func NewReloadable(path string, interval, gracePeriod time.Duration) (*Reloadable, error) {
+	if interval < 0 {
+		return nil, fmt.Errorf("negative reload interval %s", interval)
+	}
+	r := &Reloadable{
+		path:        path,
+		gracePeriod: gracePeriod,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if interval == 0 {
+		close(r.done)
+		return r, nil
+	}
+	go r.poll(interval)
+	return r, nil
+}
+
+// Codec returns the current codec
+func (r *Reloadable) Codec() *HashID {
+	return r.current.Load().hid
+}
+
+// OnChange registers fn to be called after each change of codec
+func (r *Reloadable) OnChange(fn func(previous, current *HashID)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onChange = append(r.onChange, fn)
+}
+
+// OnError registers fn to be called when the config file fails to reload while polling.
+// The current codec is kept in that case.
+func (r *Reloadable) OnError(fn func(error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = fn
+}
+
+// Reload reads the config file again if it changed since the last time, it returns whether the codec changed.
+// The OnChange hooks are called after the lock is released, so they may call the methods of r.
+func (r *Reloadable) Reload() (bool, error) {
+	previous, current, onChange, err := r.reload()
+	if err != nil || current == nil {
+		return false, err
+	}
+	if previous != nil {
+		for _, fn := range onChange {
+			fn(previous, current)
+		}
+	}
+	return true, nil
+}
+
+// reload replaces the codec if the config file changed, it returns the previous and new codecs,
+// which are nil when nothing changed, along with the hooks to call
+func (r *Reloadable) reload() (previous, current *HashID, onChange []func(previous, current *HashID), err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return nil, nil, nil, nil
+	}
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	data := NewData()
+	if err := json.Unmarshal(content, data); err != nil {
+		return nil, nil, nil, err
+	}
+	hid, err := NewWithData(data)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	r.modTime = info.ModTime()
+
+	state := &reloadState{hid: hid}
+	if previousState := r.current.Load(); previousState != nil {
+		previous = previousState.hid
+		state.previous = previous
+		state.expires = time.Now().Add(r.gracePeriod)
+	}
+	r.current.Store(state)
+	// Copy the hooks as OnChange may append to them once the lock is released
+	onChange = append([]func(previous, current *HashID){}, r.onChange...)
+	return previous, hid, onChange, nil
+}
+
+func (r *Reloadable) poll(interval time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				r.mu.Lock()
+				onError := r.onError
+				r.mu.Unlock()
+				if onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}
+}
+
+// Close stops polling the config file
+func (r *Reloadable) Close() error {
+	r.closeOnce.Do(func() { close(r.stop) })
+	<-r.done
+	return nil
+}
+
+// EncodeInt64 hashes numbers with the current codec, see HashID.EncodeInt64
+func (r *Reloadable) EncodeInt64(numbers []int64) (string, error) {
+	return r.Codec().EncodeInt64(numbers)
+}
+
+// DecodeInt64WithError unhashes hash with the current codec, see HashID.DecodeInt64WithError.
+// If it fails during the grace period after a change, the previous codec is used instead.
+func (r *Reloadable) DecodeInt64WithError(hash string) ([]int64, error) {
+	state := r.current.Load()
+	result, err := state.hid.DecodeInt64WithError(hash)
+	if err != nil && state.previous != nil && time.Now().Before(state.expires) {
+		if previous, previousErr := state.previous.DecodeInt64WithError(hash); previousErr == nil {
+			return previous, nil
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,152 @@
+package hashids
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, path, config string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashid.json")
+	now := time.Now()
+	writeConfig(t, path, `{"Salt": "first salt"}`, now)
+
+	r, err := NewReloadable(path, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	numbers := []int64{45, 434, 1313, 99}
+	firstHash, _ := r.EncodeInt64(numbers)
+
+	var changes int
+	r.OnChange(func(previous, current *HashID) {
+		changes++
+	})
+
+	if changed, err := r.Reload(); changed || err != nil {
+		t.Errorf("Expected no change but got %v, %v", changed, err)
+	}
+
+	writeConfig(t, path, `{"Salt": "second salt", "MinLength": 20}`, now.Add(time.Second))
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Fatalf("Expected a change but got %v, %v", changed, err)
+	}
+	if changes != 1 {
+		t.Errorf("Expected OnChange to be called once but got %d", changes)
+	}
+
+	secondHash, _ := r.EncodeInt64(numbers)
+	if len(secondHash) < 20 || secondHash == firstHash {
+		t.Errorf("Expected a new hash of at least 20 characters but got `%s`", secondHash)
+	}
+	for _, hash := range []string{firstHash, secondHash} {
+		dec, err := r.DecodeInt64WithError(hash)
+		if err != nil || !reflect.DeepEqual(dec, numbers) {
+			t.Errorf("Expected `%s` to decode to `%v` but got `%v`, %v", hash, numbers, dec, err)
+		}
+	}
+
+	// Invalid configs keep the current codec
+	writeConfig(t, path, `{"Alphabet": "abc"}`, now.Add(2*time.Second))
+	if _, err := r.Reload(); err == nil {
+		t.Error("Expected an error with an invalid alphabet")
+	}
+	if hash, _ := r.EncodeInt64(numbers); hash != secondHash {
+		t.Errorf("Expected hash `%s` but got `%s`", secondHash, hash)
+	}
+}
+
+func TestReloadableGracePeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashid.json")
+	now := time.Now()
+	writeConfig(t, path, `{"Salt": "first salt", "MinLength": 20}`, now)
+
+	r, err := NewReloadable(path, 10*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	changed := make(chan struct{})
+	r.OnChange(func(previous, current *HashID) {
+		close(changed)
+	})
+
+	firstHash, _ := r.EncodeInt64([]int64{1})
+	writeConfig(t, path, `{"Salt": "second salt", "MinLength": 20}`, now.Add(time.Second))
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the config to be reloaded")
+	}
+	if _, err := r.DecodeInt64WithError(firstHash); err == nil {
+		t.Errorf("Expected `%s` not to decode without grace period", firstHash)
+	}
+}
+
+func TestReloadableHooksCallMethods(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashid.json")
+	now := time.Now()
+	writeConfig(t, path, `{"Salt": "first salt"}`, now)
+
+	r, err := NewReloadable(path, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	done := make(chan struct{})
+	r.OnChange(func(previous, current *HashID) {
+		// Each of these would deadlock if the hooks were called with the lock held
+		r.OnChange(func(previous, current *HashID) {})
+		r.OnError(func(error) {})
+		if changed, err := r.Reload(); changed || err != nil {
+			t.Errorf("Expected no change from the hook but got %v, %v", changed, err)
+		}
+		close(done)
+	})
+
+	writeConfig(t, path, `{"salt": "second salt"}`, now.Add(time.Second))
+	go r.Reload()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected OnChange to be called without deadlocking")
+	}
+}
+
+func TestReloadableWithoutPolling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashid.json")
+	now := time.Now()
+	writeConfig(t, path, `{"salt": "first salt"}`, now)
+
+	if _, err := NewReloadable(path, -time.Second, 0); err == nil {
+		t.Error("Expected an error with a negative interval")
+	}
+	r, err := NewReloadable(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, path, `{"salt": "second salt"}`, now.Add(time.Second))
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Errorf("Expected a change but got %v, %v", changed, err)
+	}
+	if salt := r.Codec().Config().Salt; salt != "second salt" {
+		t.Errorf("Expected `second salt` but got `%s`", salt)
+	}
+	r.Close()
+}
@@ -0,0 +1,69 @@
+package hashids
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// FuncMap returns functions for text/template and html/template using the codecs in registry:
+//
+//	{{hashid "users" .ID}} encodes one or more integers with the codec called users
+//	{{unhashid "users" .Hash}} decodes a hash to its integers
+//	{{hashidHex "users" .Hex}} encodes a hexadecimal string
+//
+// Errors, eg. for an unknown codec or a negative number, stop the execution of the template.
+// The result can be passed to template.Funcs directly.
+func FuncMap(registry *Registry) map[string]interface{} {
+	codec := func(name string) (*HashID, error) {
+		h, ok := registry.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown hashid codec %q", name)
+		}
+		return h, nil
+	}
+	return map[string]interface{}{
+		"hashid": func(name string, values ...interface{}) (string, error) {
+			h, err := codec(name)
+			if err != nil {
+				return "", err
+			}
+			numbers := make([]int64, len(values))
+			for i, value := range values {
+				if numbers[i], err = templateInt64(value); err != nil {
+					return "", err
+				}
+			}
+			return h.EncodeInt64(numbers)
+		},
+		"unhashid": func(name, hash string) ([]int64, error) {
+			h, err := codec(name)
+			if err != nil {
+				return nil, err
+			}
+			return h.DecodeInt64WithError(hash)
+		},
+		"hashidHex": func(name, hex string) (string, error) {
+			h, err := codec(name)
+			if err != nil {
+				return "", err
+			}
+			return h.EncodeHex(hex)
+		},
+	}
+}
+
+// templateInt64 converts any integer passed to a template function
+func templateInt64(value interface{}) (int64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows int64", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	}
+	return 0, fmt.Errorf("expected an integer but got %T", value)
+}
@@ -0,0 +1,75 @@
+package hashids
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func newTemplateRegistry(t *testing.T) (*Registry, *HashID) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+	r := NewRegistry()
+	r.Register("users", hid)
+	return r, hid
+}
+
+func TestFuncMap(t *testing.T) {
+	r, hid := newTemplateRegistry(t)
+
+	tmpl := template.Must(template.New("").Funcs(FuncMap(r)).Parse(
+		`/u/{{hashid "users" .ID}} {{hashid "users" 1 .Other}} {{index (unhashid "users" .Hash) 0}} {{hashidHex "users" "abc"}}`))
+
+	hash, _ := hid.EncodeInt64([]int64{42})
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, struct {
+		ID    int64
+		Other uint8
+		Hash  string
+	}{42, 2, hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	multiple, _ := hid.EncodeInt64([]int64{1, 2})
+	hex, _ := hid.EncodeHex("abc")
+	expected := "/u/" + hash + " " + multiple + " 42 " + hex
+	if sb.String() != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, sb.String())
+	}
+}
+
+func TestFuncMapHTML(t *testing.T) {
+	r, hid := newTemplateRegistry(t)
+
+	tmpl := htmltemplate.Must(htmltemplate.New("").Funcs(FuncMap(r)).Parse(`<a href="/u/{{hashid "users" .}}">`))
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, 42); err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := hid.EncodeInt64([]int64{42})
+	if expected := `<a href="/u/` + hash + `">`; sb.String() != expected {
+		t.Errorf("Expected `%s` but got `%s`", expected, sb.String())
+	}
+}
+
+func TestFuncMapWithError(t *testing.T) {
+	r, _ := newTemplateRegistry(t)
+
+	for _, text := range []string{
+		`{{hashid "users" -1}}`,
+		`{{hashid "orders" 1}}`,
+		`{{hashid "users" "1"}}`,
+		`{{unhashid "users" "not a hash"}}`,
+		`{{hashidHex "users" "xyz"}}`,
+	} {
+		tmpl := template.Must(template.New("").Funcs(FuncMap(r)).Parse(text))
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, nil); err == nil {
+			t.Errorf("Expected error executing `%s` but got `%s`", text, sb.String())
+		}
+	}
+}
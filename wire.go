@@ -0,0 +1,185 @@
+package hashids
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// WirePolicy selects what an ID carries when it is serialized in binary formats
+type WirePolicy int
+
+const (
+	// WireInternal serializes the integer, for exchanges between trusted services
+	WireInternal WirePolicy = iota
+	// WireExternal serializes the hashid, so that the integer never leaves the process
+	WireExternal
+)
+
+func (p WirePolicy) String() string {
+	switch p {
+	case WireInternal:
+		return "internal"
+	case WireExternal:
+		return "external"
+	}
+	return fmt.Sprintf("WirePolicy(%d)", int(p))
+}
+
+// Markers starting the binary form of an ID
+const (
+	wireInternalMarker = 'i'
+	wireExternalMarker = 'e'
+)
+
+// DefaultRegistry is the Registry of IDs which were not created by Registry.ID, eg. the zero ID
+// which encoding/gob decodes into.
+//
+// It is global and mutable: any package can register codecs in it or change its WirePolicy,
+// which affects every such ID in the process. Applications which want their own codecs and policy
+// should decode into IDs created by their Registry, see ID.
+var DefaultRegistry = NewRegistry()
+
+// SetWirePolicy sets what the IDs of the Registry carry when serialized, WireInternal by default
+func (r *Registry) SetWirePolicy(policy WirePolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = policy
+}
+
+// WirePolicy returns what the IDs of the Registry carry when serialized
+func (r *Registry) WirePolicy() WirePolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// ID is an integer id bound to the codec called Codec in a Registry.
+//
+// It implements encoding.BinaryMarshaler, encoding.BinaryUnmarshaler, gob.GobEncoder and gob.GobDecoder,
+// which makes it usable with encoding/gob, CBOR libraries or as bytes in protobuf messages.
+// The binary form carries either the integer or the hashid depending on the WirePolicy of the Registry,
+// along with the codec name. Unmarshaling accepts both forms.
+//
+// An ID which was not created by Registry.ID uses DefaultRegistry. This includes the zero ID, and so the
+// fields encoding/gob or other decoders leave zero before unmarshaling: decoding a hashid then looks up
+// the codec in DefaultRegistry, and marshaling uses its WirePolicy. To use another Registry, set the
+// fields to IDs created by it before decoding, eg. wireMessage{User: r.ID("users", 0)}.
+type ID struct {
+	Value int64
+	Codec string
+
+	registry *Registry
+}
+
+// ID returns an ID bound to the codec called codec in the Registry
+func (r *Registry) ID(codec string, value int64) ID {
+	return ID{Value: value, Codec: codec, registry: r}
+}
+
+func (id ID) getRegistry() *Registry {
+	if id.registry == nil {
+		return DefaultRegistry
+	}
+	return id.registry
+}
+
+func (id ID) codec() (*HashID, error) {
+	h, ok := id.getRegistry().Get(id.Codec)
+	if !ok {
+		return nil, fmt.Errorf("unknown hashid codec %q", id.Codec)
+	}
+	return h, nil
+}
+
+// String returns the hashid of the ID
+func (id ID) String() string {
+	h, err := id.codec()
+	if err != nil {
+		return fmt.Sprintf("%%!ID(%s)", err)
+	}
+	hash, err := h.EncodeInt64([]int64{id.Value})
+	if err != nil {
+		return fmt.Sprintf("%%!ID(%s)", err)
+	}
+	return hash
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (id ID) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(id.Codec)+binary.MaxVarintLen64)
+	if id.getRegistry().WirePolicy() == WireInternal {
+		b = append(b, wireInternalMarker)
+		b = binary.AppendUvarint(b, uint64(len(id.Codec)))
+		b = append(b, id.Codec...)
+		return binary.AppendVarint(b, id.Value), nil
+	}
+
+	h, err := id.codec()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := h.EncodeInt64([]int64{id.Value})
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, wireExternalMarker)
+	b = binary.AppendUvarint(b, uint64(len(id.Codec)))
+	b = append(b, id.Codec...)
+	return append(b, hash...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// If the ID already has a Codec, the data must be for the same codec.
+func (id *ID) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty binary ID")
+	}
+	marker := data[0]
+	nameLength, n := binary.Uvarint(data[1:])
+	if n <= 0 || uint64(len(data)-1-n) < nameLength {
+		return errors.New("invalid binary ID")
+	}
+	name := string(data[1+n : 1+n+int(nameLength)])
+	payload := data[1+n+int(nameLength):]
+	if id.Codec != "" && id.Codec != name {
+		return fmt.Errorf("binary ID is for codec %q instead of %q", name, id.Codec)
+	}
+
+	decoded := ID{Codec: name, registry: id.registry}
+	switch marker {
+	case wireInternalMarker:
+		value, n := binary.Varint(payload)
+		if n <= 0 || n != len(payload) {
+			return errors.New("invalid binary ID")
+		}
+		decoded.Value = value
+	case wireExternalMarker:
+		h, err := decoded.codec()
+		if err != nil {
+			return err
+		}
+		numbers, err := h.DecodeInt64WithError(string(payload))
+		if err != nil {
+			return err
+		}
+		if len(numbers) != 1 {
+			return fmt.Errorf("expected a single number in %q but got %d", payload, len(numbers))
+		}
+		decoded.Value = numbers[0]
+	default:
+		return fmt.Errorf("invalid binary ID marker %q", marker)
+	}
+	*id = decoded
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder
+func (id ID) GobEncode() ([]byte, error) {
+	return id.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder
+func (id *ID) GobDecode(data []byte) error {
+	return id.UnmarshalBinary(data)
+}
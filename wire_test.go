@@ -0,0 +1,118 @@
+package hashids
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+)
+
+type wireMessage struct {
+	User  ID
+	Count int
+}
+
+func TestIDGob(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+
+	for _, policy := range []WirePolicy{WireInternal, WireExternal} {
+		r := NewRegistry()
+		r.Register("users", hid)
+		r.SetWirePolicy(policy)
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(wireMessage{User: r.ID("users", 42), Count: 1}); err != nil {
+			t.Fatal(err)
+		}
+		hash, _ := hid.EncodeInt64([]int64{42})
+		if carriesHash := strings.Contains(buf.String(), hash); carriesHash != (policy == WireExternal) {
+			t.Errorf("Expected %s policy to carry the hash: %v", policy, carriesHash)
+		}
+
+		data := buf.Bytes()
+
+		// Decoding with the registry of the ID
+		decoded := wireMessage{User: r.ID("users", 0)}
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.User.Value != 42 || decoded.User.Codec != "users" || decoded.Count != 1 {
+			t.Errorf("Expected user 42 but got `%+v`", decoded)
+		}
+
+		// Decoding into a zero ID uses DefaultRegistry, which does not have the codec
+		var zero wireMessage
+		err := gob.NewDecoder(bytes.NewReader(data)).Decode(&zero)
+		if policy == WireInternal {
+			if err != nil || zero.User.Value != 42 || zero.User.Codec != "users" {
+				t.Errorf("Expected user 42 but got `%+v` (%v)", zero, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), `unknown hashid codec "users"`) {
+			t.Errorf("Expected an unknown codec error but got `%v`", err)
+		}
+	}
+}
+
+func TestIDGobDefaultRegistry(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+	r := NewRegistry()
+	r.Register("wire_test", hid)
+	r.SetWirePolicy(WireExternal)
+	DefaultRegistry.Register("wire_test", hid)
+	t.Cleanup(func() { DefaultRegistry.Unregister("wire_test") })
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(wireMessage{User: r.ID("wire_test", 42)}); err != nil {
+		t.Fatal(err)
+	}
+	var decoded wireMessage
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.User != (ID{Value: 42, Codec: "wire_test"}) {
+		t.Errorf("Expected user 42 without a registry but got `%+v`", decoded.User)
+	}
+}
+
+func TestIDBinaryDefaultRegistry(t *testing.T) {
+	hdata := NewData()
+	hdata.Salt = "this is my salt"
+	hid, _ := NewWithData(hdata)
+	DefaultRegistry.Register("wire_test", hid)
+	DefaultRegistry.SetWirePolicy(WireExternal)
+	t.Cleanup(func() {
+		DefaultRegistry.Unregister("wire_test")
+		DefaultRegistry.SetWirePolicy(WireInternal)
+	})
+
+	id := ID{Value: 42, Codec: "wire_test"}
+
+	data, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ID
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != id {
+		t.Errorf("Expected `%+v` but got `%+v`", id, decoded)
+	}
+	if hash, _ := hid.EncodeInt64([]int64{42}); decoded.String() != hash {
+		t.Errorf("Expected `%s` but got `%s`", hash, decoded.String())
+	}
+
+	other := ID{Codec: "orders"}
+	if err := other.UnmarshalBinary(data); err == nil {
+		t.Error("Expected an error decoding an ID of another codec")
+	}
+	for _, invalid := range []string{"", "x", "i\x05ab", "e\x09wire_testnot a hash"} {
+		if err := decoded.UnmarshalBinary([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error decoding `%q`", invalid)
+		}
+	}
+}